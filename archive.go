@@ -0,0 +1,305 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Third party code often comes as tar, tar.gz, tar.bz2, zip or jar files.
+// WalkArchive reads the files inside one without unpacking it to disk and
+// opens archives inside it too, each file is named by the path of the
+// archive, "!/" and its name in the archive, vendor.tar.gz!/lib/foo.c.
+// An archive can be made to look small and expand to far more than fits
+// in memory, or name files like ../../etc/passwd, so ArchiveLimits caps
+// how deep, how big and how many files are read and names leaving the
+// archive stop the walk.
+
+package tagger
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// What WalkArchive will read
+type ArchiveLimits struct {
+	MaxDepth      int   // archives nested deeper are passed on as files
+	MaxMembers    int   // the most files read from all the archives
+	MaxMemberSize int64 // bigger files are skipped, uncompressed bytes
+	MaxTotalSize  int64 // the most uncompressed bytes read in all
+	MaxRatio      int64 // the most a zip file may say it expands by
+}
+
+// The limits ExtractArchiveNotices uses
+var DefaultArchiveLimits = ArchiveLimits{
+	MaxDepth:      4,
+	MaxMembers:    100000,
+	MaxMemberSize: 64 << 20,
+	MaxTotalSize:  1 << 30,
+	MaxRatio:      200,
+}
+
+// the separator between an archive and the path of a file inside it
+const archiveSeparator string = "!/"
+
+// A notice found in a file inside an archive
+type ArchiveNotice struct {
+	Notice
+	Path string `json:"path"` // vendor.tar.gz!/lib/foo.c
+}
+
+// the kinds of archive by the end of their name
+var archiveKinds = []struct {
+	suffix string
+	kind   string
+}{
+	{".tar.gz", "tgz"},
+	{".tgz", "tgz"},
+	{".tar.bz2", "tbz"},
+	{".tbz2", "tbz"},
+	{".tbz", "tbz"},
+	{".tar", "tar"},
+	{".zip", "zip"},
+	{".jar", "zip"},
+	{".war", "zip"},
+	{".ear", "zip"},
+	{".gz", "gz"},
+	{".bz2", "bz2"},
+}
+
+// Returns what kind of archive the name is, "" when it is not one
+func archiveKind(name string) string {
+	lower := strings.ToLower(name)
+	for _, kind := range archiveKinds {
+		if strings.HasSuffix(lower, kind.suffix) {
+			return kind.kind
+		}
+	}
+	return ""
+}
+
+// Returns true if WalkArchive can read the file with this name
+func IsArchive(name string) bool {
+	return archiveKind(name) != ""
+}
+
+// Where a walk is, shared by the archives nested in the first
+type archiveWalk struct {
+	limits  ArchiveLimits
+	walk    func(path string, data []byte) error
+	members int
+	total   int64
+}
+
+// Calls walk with the path and contents of every regular file in the
+// archive, archives in it are walked in turn. The archive's kind comes
+// from its name. Reading stops at the first error walk returns, at a file
+// named outside the archive and when the limits are passed.
+func WalkArchive(name string, in io.Reader, limits ArchiveLimits, walk func(path string, data []byte) error) error {
+	state := &archiveWalk{limits: limits, walk: walk}
+	return state.archive(name, in, 0)
+}
+
+// Walks one archive depth archives down
+func (state *archiveWalk) archive(name string, in io.Reader, depth int) error {
+	switch archiveKind(name) {
+	case "tar":
+		return state.tar(name, in, depth)
+	case "tgz", "gz":
+		unzipped, err := gzip.NewReader(in)
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		defer unzipped.Close()
+		if archiveKind(name) == "tgz" {
+			return state.tar(name, unzipped, depth)
+		}
+		return state.single(name, unzipped, depth)
+	case "tbz":
+		return state.tar(name, bzip2.NewReader(in), depth)
+	case "bz2":
+		return state.single(name, bzip2.NewReader(in), depth)
+	case "zip":
+		return state.zip(name, in, depth)
+	}
+	return fmt.Errorf("%s: not an archive", name)
+}
+
+// Walks a tar file
+func (state *archiveWalk) tar(name string, in io.Reader, depth int) error {
+	reader := tar.NewReader(in)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		memberPath, err := archiveMemberPath(name, header.Name)
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if header.Size > state.limits.MaxMemberSize {
+			continue
+		}
+		if err := state.member(memberPath, reader, depth); err != nil {
+			return err
+		}
+	}
+}
+
+// Walks a .gz or .bz2 file that is not a tar, the one file in it is
+// named without the ending
+func (state *archiveWalk) single(name string, in io.Reader, depth int) error {
+	base := path.Base(strings.ReplaceAll(name, archiveSeparator, "/"))
+	return state.member(name+archiveSeparator+base[:len(base)-len(path.Ext(base))], in, depth)
+}
+
+// Walks a zip file, it has to be read from the end so it is read into
+// memory unless it can be read at any offset already
+func (state *archiveWalk) zip(name string, in io.Reader, depth int) error {
+	readerAt, size, ok := readerAtOf(in)
+	if !ok {
+		data, err := state.read(name, in)
+		if err != nil || data == nil {
+			return err
+		}
+		readerAt, size = bytes.NewReader(data), int64(len(data))
+	}
+	reader, err := zip.NewReader(readerAt, size)
+	if err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+
+	for _, file := range reader.File {
+		memberPath, err := archiveMemberPath(name, file.Name)
+		if err != nil {
+			return err
+		}
+		if !file.Mode().IsRegular() || file.UncompressedSize64 > uint64(state.limits.MaxMemberSize) {
+			continue
+		}
+		if file.CompressedSize64 > 0 && file.UncompressedSize64/file.CompressedSize64 > uint64(state.limits.MaxRatio) {
+			return fmt.Errorf("%s: expands %d times, it looks like a zip bomb", memberPath, file.UncompressedSize64/file.CompressedSize64)
+		}
+		contents, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("%s: %v", memberPath, err)
+		}
+		err = state.member(memberPath, contents, depth)
+		contents.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reads one file of an archive and walks it, or the archive it is
+func (state *archiveWalk) member(memberPath string, in io.Reader, depth int) error {
+	state.members++
+	if state.members > state.limits.MaxMembers {
+		return fmt.Errorf("%s: more than %d files in the archive", memberPath, state.limits.MaxMembers)
+	}
+	data, err := state.read(memberPath, in)
+	if err != nil || data == nil {
+		return err
+	}
+	if IsArchive(memberPath) && depth < state.limits.MaxDepth {
+		return state.archive(memberPath, bytes.NewReader(data), depth+1)
+	}
+	return state.walk(memberPath, data)
+}
+
+// Reads at most MaxMemberSize bytes, nil when there are more. The size
+// an archive says a file has is not trusted, what is read counts
+// towards MaxTotalSize.
+func (state *archiveWalk) read(name string, in io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(in, state.limits.MaxMemberSize+1))
+	state.total += int64(len(data))
+	if state.total > state.limits.MaxTotalSize {
+		return nil, fmt.Errorf("%s: more than %d bytes in the archive, it looks like a zip bomb", name, state.limits.MaxTotalSize)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	if int64(len(data)) > state.limits.MaxMemberSize {
+		return nil, nil
+	}
+	return data, nil
+}
+
+// Returns the path of a file in the archive, an error when its name is
+// absolute, starts with a drive letter or goes up out of the archive
+func archiveMemberPath(archive string, name string) (string, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	clean := path.Clean(name)
+	drive := len(name) > 1 && name[1] == ':'
+	if path.IsAbs(name) || drive || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("%s: %q is outside the archive", archive, name)
+	}
+	return archive + archiveSeparator + clean, nil
+}
+
+// Returns the input as an io.ReaderAt and its size when it is one
+func readerAtOf(in io.Reader) (io.ReaderAt, int64, bool) {
+	switch reader := in.(type) {
+	case *os.File:
+		info, err := reader.Stat()
+		if err == nil && info.Mode().IsRegular() {
+			return reader, info.Size(), true
+		}
+	case interface {
+		io.ReaderAt
+		Size() int64
+	}:
+		return reader, reader.Size(), true
+	}
+	return nil, 0, false
+}
+
+// Finds the notices in every file in the archive. The notices found
+// before an error are returned with it.
+func (copyrightTagger *Tagger) ExtractArchiveNotices(name string, in io.Reader) ([]ArchiveNotice, error) {
+	var notices = make([]ArchiveNotice, 0)
+	err := WalkArchive(name, in, DefaultArchiveLimits, func(memberPath string, data []byte) error {
+		for _, notice := range copyrightTagger.ExtractNotices(data) {
+			notices = append(notices, ArchiveNotice{Notice: notice, Path: memberPath})
+		}
+		return nil
+	})
+	return notices, err
+}
@@ -0,0 +1,209 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Unit tests for walking archives
+
+package tagger
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// a file in a test archive
+type archiveFile struct {
+	name     string
+	contents string
+}
+
+func mkTar(t *testing.T, files []archiveFile) []byte {
+	var buf bytes.Buffer
+	writer := tar.NewWriter(&buf)
+	for _, file := range files {
+		header := &tar.Header{Name: file.name, Mode: 0644, Size: int64(len(file.contents)), Typeflag: tar.TypeReg}
+		if err := writer.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := writer.Write([]byte(file.contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func mkGzip(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func mkZip(t *testing.T, files []archiveFile) []byte {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for _, file := range files {
+		contents, err := writer.Create(file.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := contents.Write([]byte(file.contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestWalkArchive(t *testing.T) {
+	inner := mkZip(t, []archiveFile{
+		{"META-INF/NOTICE", "Copyright 2012 Inner Corp"},
+		{"deeper.tar", string(mkTar(t, []archiveFile{{"x.txt", "deep"}}))},
+	})
+	outer := mkGzip(t, mkTar(t, []archiveFile{
+		{"lib/foo.c", "/* Copyright 2010 Jane Doe */"},
+		{"./lib/../README", "read me"},
+		{"lib/inner.jar", string(inner)},
+		{"notes.txt.gz", string(mkGzip(t, []byte("zipped notes")))},
+	}))
+
+	var paths []string
+	err := WalkArchive("vendor.tar.gz", bytes.NewReader(outer), DefaultArchiveLimits, func(path string, data []byte) error {
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := []string{
+		"vendor.tar.gz!/README",
+		"vendor.tar.gz!/lib/foo.c",
+		"vendor.tar.gz!/lib/inner.jar!/META-INF/NOTICE",
+		"vendor.tar.gz!/lib/inner.jar!/deeper.tar!/x.txt",
+		"vendor.tar.gz!/notes.txt.gz!/notes.txt",
+	}
+	sort.Strings(paths)
+	if strings.Join(paths, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected %q got %q", expected, paths)
+	}
+
+	// too deep archives are passed on as they are
+	limits := DefaultArchiveLimits
+	limits.MaxDepth = 1
+	paths = nil
+	WalkArchive("vendor.tar.gz", bytes.NewReader(outer), limits, func(path string, data []byte) error {
+		paths = append(paths, path)
+		return nil
+	})
+	found := false
+	for _, path := range paths {
+		found = found || path == "vendor.tar.gz!/lib/inner.jar!/deeper.tar"
+	}
+	if !found {
+		t.Errorf("expected deeper.tar as a file got %q", paths)
+	}
+
+	notices, err := copyrightTagger.ExtractArchiveNotices("vendor.tar.gz", bytes.NewReader(outer))
+	if err != nil || len(notices) != 2 {
+		t.Fatalf("expected 2 notices got %d: %v", len(notices), err)
+	}
+	if notices[0].Path != "vendor.tar.gz!/lib/foo.c" || !strings.HasPrefix(notices[0].Text, "Copyright 2010 Jane Doe") {
+		t.Errorf("expected Jane Doe in lib/foo.c got %q in %s", notices[0].Text, notices[0].Path)
+	}
+}
+
+func TestArchiveGuards(t *testing.T) {
+	type GuardTest struct {
+		Expected string // in the error
+		Name     string
+		Data     []byte
+		Limits   ArchiveLimits
+	}
+
+	small := DefaultArchiveLimits
+	small.MaxTotalSize = 1000
+	few := DefaultArchiveLimits
+	few.MaxMembers = 1
+	zeros := strings.Repeat("\x00", 1<<20)
+
+	tests := []GuardTest{
+		{
+			Expected: "outside the archive",
+			Name:     "evil.tar",
+			Data:     mkTar(t, []archiveFile{{"../../etc/passwd", "root"}}),
+			Limits:   DefaultArchiveLimits,
+		},
+		{
+			Expected: "outside the archive",
+			Name:     "evil.zip",
+			Data:     mkZip(t, []archiveFile{{"/etc/passwd", "root"}}),
+			Limits:   DefaultArchiveLimits,
+		},
+		{
+			Expected: "zip bomb",
+			Name:     "bomb.zip",
+			Data:     mkZip(t, []archiveFile{{"zeros", zeros}}),
+			Limits:   DefaultArchiveLimits,
+		},
+		{
+			Expected: "zip bomb",
+			Name:     "bomb.tar.gz",
+			Data:     mkGzip(t, mkTar(t, []archiveFile{{"a", zeros[:600]}, {"b", zeros[:600]}})),
+			Limits:   small,
+		},
+		{
+			Expected: "more than 1 files",
+			Name:     "many.tar",
+			Data:     mkTar(t, []archiveFile{{"a", "a"}, {"b", "b"}}),
+			Limits:   few,
+		},
+	}
+
+	for i, test := range tests {
+		err := WalkArchive(test.Name, bytes.NewReader(test.Data), test.Limits, func(path string, data []byte) error {
+			return nil
+		})
+		if err == nil || !strings.Contains(err.Error(), test.Expected) {
+			t.Errorf("Test %d: expected an error with %q got %v", i, test.Expected, err)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// This file is about who wrote a file as opposed to who holds its
+// copyright. Lines like "Written by", "Author:", "@author", "Contributed
+// by", "Maintainer:" and "Originally by" name people and organizations,
+// the names are the proper nouns the tagger finds after the trigger on the
+// same line and each email goes with the name right before it. AUTHORS
+// and CONTRIBUTORS files are read a line at a time into the same Author.
+
+package tagger
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// the roles an author can have
+const (
+	RoleAuthor      string = "author"
+	RoleContributor string = "contributor"
+	RoleMaintainer  string = "maintainer"
+)
+
+// A person or organization credited with some work
+type Author struct {
+	Name  string `json:"name"`  // "" when only an email was given
+	Email string `json:"email"` // "" when only a name was given
+	Role  string `json:"role"`  // RoleAuthor, RoleContributor or RoleMaintainer
+	Start int    `json:"start"` // byte offset of the name, or the email
+	End   int    `json:"end"`   // byte offset just past the email, or the name
+}
+
+// A phrase that is followed by who wrote something
+type authorTrigger struct {
+	role    string
+	pattern *regexp.Regexp
+}
+
+// the longer phrases go first
+var authorTriggers = []authorTrigger{
+	{RoleAuthor, regexp.MustCompile(`(?i)\boriginally\s+(?:written\s+)?by\b`)},
+	{RoleAuthor, regexp.MustCompile(`(?i)\bwritten\s+by\b`)},
+	{RoleAuthor, regexp.MustCompile(`(?i)\bcreated\s+by\b`)},
+	{RoleAuthor, regexp.MustCompile(`(?i)@authors?\b`)},
+	{RoleAuthor, regexp.MustCompile(`(?i)\bauthors?\s*:`)},
+	{RoleContributor, regexp.MustCompile(`(?i)\bcontributed\s+by\b`)},
+	{RoleContributor, regexp.MustCompile(`(?i)\bcontributors?\s*:`)},
+	{RoleMaintainer, regexp.MustCompile(`(?i)\bmaintained\s+by\b`)},
+	{RoleMaintainer, regexp.MustCompile(`(?i)\bmaintainers?\s*:`)},
+}
+
+// an email, with or without the <> around it
+var authorEmailPattern = regexp.MustCompile(`<?[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)+>?`)
+
+// things at the start of a line of an AUTHORS file that are not the name
+const authorsFileLeaders string = " \t*-•+"
+
+// Given any input this returns the authors, contributors and maintainers
+// named in it in the order they appear
+func (copyrightTagger *Tagger) ExtractAuthors(inBytes []byte) []Author {
+	var authors = make([]Author, 0)
+
+	for lineStart := 0; lineStart < len(inBytes); {
+		lineEnd := lineStart
+		for lineEnd < len(inBytes) && inBytes[lineEnd] != '\n' {
+			lineEnd++
+		}
+		line := inBytes[lineStart:lineEnd]
+
+		// the trigger that comes first on the line
+		role, after := "", -1
+		for _, trigger := range authorTriggers {
+			if span := trigger.pattern.FindIndex(line); span != nil && (after < 0 || span[0] < after) {
+				role, after = trigger.role, span[1]
+			}
+		}
+		if role != "" {
+			for _, author := range copyrightTagger.lineAuthors(line[after:], role) {
+				author.Start += lineStart + after
+				author.End += lineStart + after
+				authors = append(authors, author)
+			}
+		}
+		lineStart = lineEnd + 1
+	}
+	return authors
+}
+
+// Returns the authors in the rest of a line after its trigger, the
+// offsets are offsets into rest
+func (copyrightTagger *Tagger) lineAuthors(rest []byte, role string) []Author {
+	var authors = make([]Author, 0)
+	emails := authorEmailPattern.FindAllIndex(rest, -1)
+	inEmail := func(word TaggedWord) bool {
+		for _, email := range emails {
+			if word.Start < email[1] && email[0] < word.End {
+				return true
+			}
+		}
+		return false
+	}
+
+	// the names are runs of proper nouns, a comma only splits them when
+	// what follows is not a corporate ending
+	words := copyrightTagger.TagBytes(rest)
+	runStart := -1
+	runEnd := -1
+	flush := func() {
+		if runStart >= 0 {
+			end := words[runEnd].End
+			// the tagger keeps the period ending a sentence on the name
+			last := words[runEnd].Text
+			if strings.HasSuffix(last, ".") && len(last) > 2 && !isCorporateSuffix(words[runEnd]) {
+				end--
+			}
+			authors = append(authors, Author{
+				Name:  string(rest[words[runStart].Start:end]),
+				Role:  role,
+				Start: words[runStart].Start,
+				End:   end,
+			})
+		}
+		runStart = -1
+	}
+	for i, word := range words {
+		switch {
+		case word.Tag == "np" && !inEmail(word) && !noticeKeywords[strings.ToLower(word.Text)]:
+			if runStart < 0 {
+				runStart = i
+			}
+			runEnd = i
+		case word.Tag == "," && runStart >= 0 && i+1 < len(words) && isCorporateSuffix(words[i+1]):
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	// each email goes with the name right before it
+	for _, email := range emails {
+		address := strings.Trim(string(rest[email[0]:email[1]]), "<>")
+		owner := -1
+		for i := range authors {
+			if authors[i].End <= email[0] && authors[i].Email == "" {
+				owner = i
+			}
+		}
+		if owner >= 0 && !hasNameBetween(authors, authors[owner].End, email[0]) {
+			authors[owner].Email = address
+			authors[owner].End = email[1]
+			continue
+		}
+		authors = append(authors, Author{Email: address, Role: role, Start: email[0], End: email[1]})
+	}
+	sort.SliceStable(authors, func(i, j int) bool {
+		return authors[i].Start < authors[j].Start
+	})
+	return authors
+}
+
+// returns true if a name starts between the offsets
+func hasNameBetween(authors []Author, start int, end int) bool {
+	for _, author := range authors {
+		if author.Name != "" && author.Start >= start && author.Start < end {
+			return true
+		}
+	}
+	return false
+}
+
+// Reads an AUTHORS, CONTRIBUTORS or MAINTAINERS file, one author a line
+// written as "Name", "Name <email>", "Name (email)" or just the email.
+// Blank lines, # comments and lines ending in a colon are skipped. The
+// role comes from the name of the file.
+func ParseAuthorsFile(path string, inBytes []byte) []Author {
+	var authors = make([]Author, 0)
+
+	role := RoleAuthor
+	switch base := strings.ToUpper(filepath.Base(path)); {
+	case strings.HasPrefix(base, "CONTRIBUTORS"):
+		role = RoleContributor
+	case strings.HasPrefix(base, "MAINTAINERS"):
+		role = RoleMaintainer
+	}
+
+	for lineStart := 0; lineStart < len(inBytes); {
+		lineEnd := lineStart
+		for lineEnd < len(inBytes) && inBytes[lineEnd] != '\n' {
+			lineEnd++
+		}
+		line := string(inBytes[lineStart:lineEnd])
+		trimmed := strings.TrimRight(strings.TrimLeft(line, authorsFileLeaders), " \t\r")
+		start := lineStart + len(line) - len(strings.TrimLeft(line, authorsFileLeaders))
+		lineStart = lineEnd + 1
+
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasSuffix(trimmed, ":") {
+			continue
+		}
+
+		author := Author{Role: role, Start: start, End: start + len(trimmed)}
+		name := trimmed
+		if email := authorEmailPattern.FindStringIndex(trimmed); email != nil {
+			author.Email = strings.Trim(trimmed[email[0]:email[1]], "<>")
+			name = trimmed[:email[0]]
+		}
+		// Name (email) and Name (Company) keep only the Name
+		if paren := strings.Index(name, "("); paren > 0 {
+			name = name[:paren]
+		}
+		author.Name = strings.TrimSpace(strings.TrimRight(strings.TrimSpace(name), ",("))
+		authors = append(authors, author)
+	}
+	return authors
+}
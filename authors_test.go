@@ -0,0 +1,114 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Unit tests for author extraction
+
+package tagger
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractAuthors(t *testing.T) {
+	type AuthorTest struct {
+		Expected []Author // only Name, Email and Role are checked
+		Text     string
+	}
+
+	tests := []AuthorTest{
+		{
+			Expected: []Author{{Name: "Eric Knapik", Email: "eric@example.com", Role: RoleAuthor}},
+			Text:     "// Author: Eric Knapik <eric@example.com>\n",
+		},
+		{
+			Expected: []Author{{Name: "Jane Doe", Role: RoleAuthor}, {Name: "John Smith", Role: RoleAuthor}},
+			Text:     "/* Written by Jane Doe and John Smith */",
+		},
+		{
+			Expected: []Author{{Name: "Theodore Tso", Email: "tytso@mit.edu", Role: RoleAuthor}},
+			Text:     " * @author Theodore Tso (tytso@mit.edu)",
+		},
+		{
+			Expected: []Author{{Name: "Free Software Foundation, Inc.", Role: RoleContributor}},
+			Text:     "# Contributed by the Free Software Foundation, Inc.",
+		},
+		{
+			Expected: []Author{{Email: "team@example.org", Role: RoleMaintainer}},
+			Text:     "Maintainer: team@example.org",
+		},
+		{
+			Expected: []Author{{Name: "Alastair Houghton", Role: RoleAuthor}},
+			Text:     "Originally by Alastair Houghton.\nnothing here by Nobody",
+		},
+	}
+
+	for i, test := range tests {
+		authors := copyrightTagger.ExtractAuthors([]byte(test.Text))
+		var got []Author
+		for _, author := range authors {
+			if test.Text[author.Start:author.End] == "" {
+				t.Errorf("Test %d: empty span for %+v", i, author)
+			}
+			got = append(got, Author{Name: author.Name, Email: author.Email, Role: author.Role})
+		}
+		if !reflect.DeepEqual(got, test.Expected) {
+			t.Errorf("Test %d: expected %+v got %+v", i, test.Expected, got)
+		}
+	}
+}
+
+func TestParseAuthorsFile(t *testing.T) {
+	text := "# This is the official list of authors.\n" +
+		"The following people have contributed:\n\n" +
+		"Eric Knapik <eric@example.com>\n" +
+		"  * Jane Doe (Acme Corp)\n" +
+		"Google Inc.\n" +
+		"nobody@example.org\n"
+
+	expected := []Author{
+		{Name: "Eric Knapik", Email: "eric@example.com", Role: RoleContributor},
+		{Name: "Jane Doe", Role: RoleContributor},
+		{Name: "Google Inc.", Role: RoleContributor},
+		{Email: "nobody@example.org", Role: RoleContributor},
+	}
+	authors := ParseAuthorsFile("src/CONTRIBUTORS", []byte(text))
+	var got []Author
+	for _, author := range authors {
+		got = append(got, Author{Name: author.Name, Email: author.Email, Role: author.Role})
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %+v got %+v", expected, got)
+	}
+	if text[authors[1].Start:authors[1].End] != "Jane Doe (Acme Corp)" {
+		t.Errorf("unexpected span %q", text[authors[1].Start:authors[1].End])
+	}
+	if ParseAuthorsFile("AUTHORS", []byte("X"))[0].Role != RoleAuthor {
+		t.Errorf("expected AUTHORS to list authors")
+	}
+}
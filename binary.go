@@ -0,0 +1,200 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Shipped binaries carry the notices of the static libraries linked into
+// them. This opens ELF, PE and Mach-O files with the standard library,
+// pulls the printable strings out of the read only data and comment
+// sections, the same as strings(1) does, and looks for notices in each.
+
+package tagger
+
+import (
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// the shortest string looked at, "(c) 1999 X" is not much longer
+const minBinaryString int = 8
+
+// the sections strings are read from for each format, a name ending in
+// a period takes every section it begins, .note. is .note.ABI-tag,
+// .note.gnu.build-id and the rest
+var binarySections = map[string]map[string]bool{
+	"elf":   {".rodata": true, ".rodata1": true, ".comment": true, ".note": true, ".note.": true},
+	"pe":    {".rdata": true, ".rodata": true},
+	"macho": {"__cstring": true, "__const": true, "__comment": true},
+}
+
+// A notice found in a binary. The Start and End of the Notice are offsets
+// in the section, StartPos and EndPos are not set.
+type BinaryNotice struct {
+	Notice
+	Format  string `json:"format"`  // "elf", "pe" or "macho"
+	Section string `json:"section"` // the section the notice is in
+	// the offset of the notice in the file, -1 when the section is
+	// compressed and the notice is not in the file as it is
+	FileOffset int64 `json:"file_offset"`
+}
+
+// One section strings are read from
+type binarySection struct {
+	name   string
+	offset int64 // in the file, -1 when compressed
+	data   func() ([]byte, error)
+}
+
+// Finds the notices in the strings of an ELF, PE or Mach-O file
+func (copyrightTagger *Tagger) ExtractBinaryNotices(in io.ReaderAt) ([]BinaryNotice, error) {
+	format, sections, err := binarySectionsOf(in)
+	if err != nil {
+		return nil, err
+	}
+
+	var notices = make([]BinaryNotice, 0)
+	for _, section := range sections {
+		data, err := section.data()
+		if err != nil {
+			return nil, fmt.Errorf("%s section %s: %v", format, section.name, err)
+		}
+		for _, str := range binaryStrings(data) {
+			for _, notice := range copyrightTagger.ExtractNotices(data[str[0]:str[1]]) {
+				notice.Start += str[0]
+				notice.End += str[0]
+				for i := range notice.Words {
+					notice.Words[i].Start += str[0]
+					notice.Words[i].End += str[0]
+				}
+				notice.StartPos = Position{}
+				notice.EndPos = Position{}
+
+				found := BinaryNotice{Notice: notice, Format: format, Section: section.name, FileOffset: -1}
+				if section.offset >= 0 {
+					found.FileOffset = section.offset + int64(notice.Start)
+				}
+				notices = append(notices, found)
+			}
+		}
+	}
+	return notices, nil
+}
+
+// Opens the binary as whichever format it is and returns the sections
+// to read strings from
+func binarySectionsOf(in io.ReaderAt) (string, []binarySection, error) {
+	var sections = make([]binarySection, 0)
+
+	if file, err := elf.NewFile(in); err == nil {
+		for _, section := range file.Sections {
+			if !isBinarySection("elf", section.Name) || section.Type == elf.SHT_NOBITS {
+				continue
+			}
+			offset := int64(section.Offset)
+			if section.Flags&elf.SHF_COMPRESSED != 0 {
+				offset = -1
+			}
+			sections = append(sections, binarySection{section.Name, offset, section.Data})
+		}
+		return "elf", sections, nil
+	}
+
+	if file, err := pe.NewFile(in); err == nil {
+		for _, section := range file.Sections {
+			if !isBinarySection("pe", section.Name) {
+				continue
+			}
+			sections = append(sections, binarySection{section.Name, int64(section.Offset), section.Data})
+		}
+		return "pe", sections, nil
+	}
+
+	if file, err := macho.NewFile(in); err == nil {
+		for _, section := range file.Sections {
+			if !isBinarySection("macho", section.Name) || section.Offset == 0 {
+				continue
+			}
+			sections = append(sections, binarySection{section.Seg + "," + section.Name, int64(section.Offset), section.Data})
+		}
+		return "macho", sections, nil
+	}
+
+	// a universal binary is a Mach-O file for each architecture one after
+	// the other, their offsets are from the start of their architecture
+	if file, err := macho.NewFatFile(in); err == nil {
+		for _, arch := range file.Arches {
+			for _, section := range arch.Sections {
+				if !isBinarySection("macho", section.Name) || section.Offset == 0 {
+					continue
+				}
+				name := fmt.Sprintf("%v:%s,%s", arch.Cpu, section.Seg, section.Name)
+				sections = append(sections, binarySection{name, int64(arch.Offset) + int64(section.Offset), section.Data})
+			}
+		}
+		return "macho", sections, nil
+	}
+
+	return "", nil, fmt.Errorf("not an ELF, PE or Mach-O file")
+}
+
+// Returns true when strings are read from the section of the format
+func isBinarySection(format string, name string) bool {
+	for section := range binarySections[format] {
+		if name == section || (strings.HasSuffix(section, ".") && strings.HasPrefix(name, section)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the start and end of every run of at least minBinaryString
+// bytes of printable UTF-8 in the data
+func binaryStrings(data []byte) [][2]int {
+	var strs = make([][2]int, 0)
+	start := 0
+	for at := 0; at < len(data); {
+		r, size := utf8.DecodeRune(data[at:])
+		if (r == utf8.RuneError && size < 2) || (!unicode.IsPrint(r) && r != '\t') {
+			if at-start >= minBinaryString {
+				strs = append(strs, [2]int{start, at})
+			}
+			at += size
+			start = at
+			continue
+		}
+		at += size
+	}
+	if len(data)-start >= minBinaryString {
+		strs = append(strs, [2]int{start, len(data)})
+	}
+	return strs
+}
@@ -0,0 +1,245 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Unit tests for finding notices in binaries
+
+package tagger
+
+import (
+	"bytes"
+	"debug/elf"
+	"debug/macho"
+	"encoding/binary"
+	"os"
+	"strings"
+	"testing"
+)
+
+// linked into the test binary as a string TestBinaryNotices looks for
+var binaryTestNotice = "\x00Copyright 2016 Binary Tester\x00"
+
+func TestBinaryStrings(t *testing.T) {
+	type StringsTest struct {
+		Expected []string
+		Data     string
+	}
+
+	tests := []StringsTest{
+		{Expected: []string{"hello world"}, Data: "\x00\x01hello world\x00ab\x00"},
+		{Expected: []string{"© 2001 Acme", "long enough"}, Data: "© 2001 Acme\xfflong enough"},
+		{Expected: []string{}, Data: "short\x00strs\x00"},
+	}
+
+	for i, test := range tests {
+		strs := binaryStrings([]byte(test.Data))
+		if len(strs) != len(test.Expected) {
+			t.Errorf("Test %d: expected %q got %v", i, test.Expected, strs)
+			continue
+		}
+		for j, str := range strs {
+			if got := test.Data[str[0]:str[1]]; got != test.Expected[j] {
+				t.Errorf("Test %d: expected %q got %q", i, test.Expected[j], got)
+			}
+		}
+	}
+}
+
+func TestBinaryNotices(t *testing.T) {
+	path, err := os.Executable()
+	if err != nil {
+		t.Skipf("no test binary: %v", err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Skipf("can not open the test binary: %v", err)
+	}
+	defer file.Close()
+
+	notices, err := copyrightTagger.ExtractBinaryNotices(file)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	found := false
+	for _, notice := range notices {
+		if !strings.HasPrefix(notice.Text, "Copyright 2016 Binary Tester") {
+			continue
+		}
+		found = true
+		if notice.FileOffset < 0 {
+			continue
+		}
+		inFile := make([]byte, len(notice.Text))
+		if _, err := file.ReadAt(inFile, notice.FileOffset); err != nil || string(inFile) != notice.Text {
+			t.Errorf("expected %q at file offset %d got %q", notice.Text, notice.FileOffset, inFile)
+		}
+	}
+	if !found {
+		t.Errorf("expected the notice in %q in the test binary", binaryTestNotice)
+	}
+
+	if _, err := copyrightTagger.ExtractBinaryNotices(bytes.NewReader([]byte("Copyright 2016 Jane Doe"))); err == nil {
+		t.Errorf("expected an error for text")
+	}
+}
+
+// builds a little endian 64 bit ELF file with one section besides the
+// section names
+func mkELF(name string, sectionType elf.SectionType, data []byte) []byte {
+	names := "\x00" + name + "\x00.shstrtab\x00"
+	dataOffset := 64
+	namesOffset := dataOffset + len(data)
+	headersOffset := (namesOffset + len(names) + 7) &^ 7
+
+	var out bytes.Buffer
+	header := elf.Header64{
+		Type:      uint16(elf.ET_REL),
+		Machine:   uint16(elf.EM_X86_64),
+		Version:   uint32(elf.EV_CURRENT),
+		Shoff:     uint64(headersOffset),
+		Ehsize:    64,
+		Shentsize: 64,
+		Shnum:     3,
+		Shstrndx:  2,
+	}
+	copy(header.Ident[:], elf.ELFMAG)
+	header.Ident[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	header.Ident[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	header.Ident[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+	binary.Write(&out, binary.LittleEndian, header)
+	out.Write(data)
+	out.WriteString(names)
+	out.Write(make([]byte, headersOffset-out.Len()))
+
+	sections := []elf.Section64{
+		{},
+		{Name: 1, Type: uint32(sectionType), Off: uint64(dataOffset), Size: uint64(len(data)), Addralign: 1},
+		{Name: uint32(len(name) + 2), Type: uint32(elf.SHT_STRTAB), Off: uint64(namesOffset), Size: uint64(len(names)), Addralign: 1},
+	}
+	binary.Write(&out, binary.LittleEndian, sections)
+	return out.Bytes()
+}
+
+func TestBinaryNoteSections(t *testing.T) {
+	type SectionTest struct {
+		Expected bool // whether the notice is found
+		Name     string
+	}
+
+	tests := []SectionTest{
+		{Expected: true, Name: ".note"},
+		{Expected: true, Name: ".note.vendor"},
+		{Expected: true, Name: ".note.gnu.build-id"},
+		{Expected: false, Name: ".notes"},
+		{Expected: false, Name: ".text"},
+	}
+
+	data := []byte("\x00\x00\x00\x00Copyright 2019 Note Vendor Inc.\x00")
+	for i, test := range tests {
+		in := mkELF(test.Name, elf.SHT_NOTE, data)
+		notices, err := copyrightTagger.ExtractBinaryNotices(bytes.NewReader(in))
+		if err != nil {
+			t.Errorf("Test %d: unexpected error %v", i, err)
+			continue
+		}
+		found := len(notices) == 1 && strings.HasPrefix(notices[0].Text, "Copyright 2019 Note Vendor") && notices[0].Section == test.Name
+		if found != test.Expected {
+			t.Errorf("Test %d: expected found %v in %s got %v", i, test.Expected, test.Name, notices)
+		}
+		if found && notices[0].FileOffset != 64+4 {
+			t.Errorf("Test %d: expected the notice at file offset 68 got %d", i, notices[0].FileOffset)
+		}
+	}
+}
+
+// builds a 64 bit Mach-O file with the data in __TEXT,__cstring
+func mkMachO(cpu macho.Cpu, data []byte) []byte {
+	const headerSize, segmentSize, sectionSize = 32, 72, 80
+	dataOffset := headerSize + segmentSize + sectionSize
+
+	var out bytes.Buffer
+	binary.Write(&out, binary.LittleEndian, macho.FileHeader{
+		Magic: macho.Magic64,
+		Cpu:   cpu,
+		Type:  macho.TypeExec,
+		Ncmd:  1,
+		Cmdsz: segmentSize + sectionSize,
+	})
+	binary.Write(&out, binary.LittleEndian, uint32(0)) // reserved
+	segment := macho.Segment64{
+		Cmd:    macho.LoadCmdSegment64,
+		Len:    segmentSize + sectionSize,
+		Offset: uint64(dataOffset),
+		Filesz: uint64(len(data)),
+		Memsz:  uint64(len(data)),
+		Nsect:  1,
+	}
+	copy(segment.Name[:], "__TEXT")
+	binary.Write(&out, binary.LittleEndian, segment)
+	section := macho.Section64{Size: uint64(len(data)), Offset: uint32(dataOffset)}
+	copy(section.Name[:], "__cstring")
+	copy(section.Seg[:], "__TEXT")
+	binary.Write(&out, binary.LittleEndian, section)
+	out.Write(data)
+	return out.Bytes()
+}
+
+func TestBinaryUniversal(t *testing.T) {
+	data := []byte("\x00Copyright 2021 Fat Binary Inc.\x00")
+	arches := [][]byte{mkMachO(macho.CpuAmd64, data), mkMachO(macho.CpuArm64, data)}
+
+	// the fat header is big endian, each architecture starts at 4096
+	var fat bytes.Buffer
+	binary.Write(&fat, binary.BigEndian, []uint32{macho.MagicFat, uint32(len(arches))})
+	for i, arch := range arches {
+		cpu := macho.CpuAmd64
+		if i == 1 {
+			cpu = macho.CpuArm64
+		}
+		binary.Write(&fat, binary.BigEndian, []uint32{uint32(cpu), 0, uint32(4096 * (i + 1)), uint32(len(arch)), 12})
+	}
+	for i, arch := range arches {
+		fat.Write(make([]byte, 4096*(i+1)-fat.Len()))
+		fat.Write(arch)
+	}
+	in := fat.Bytes()
+
+	notices, err := copyrightTagger.ExtractBinaryNotices(bytes.NewReader(in))
+	if err != nil || len(notices) != 2 {
+		t.Fatalf("expected a notice for each architecture got %v %v", notices, err)
+	}
+	for i, notice := range notices {
+		if notice.Format != "macho" || !strings.HasSuffix(notice.Section, "__TEXT,__cstring") {
+			t.Errorf("Test %d: unexpected format %s and section %s", i, notice.Format, notice.Section)
+		}
+		if notice.FileOffset < 0 || notice.FileOffset+int64(len(notice.Text)) > int64(len(in)) ||
+			string(in[notice.FileOffset:notice.FileOffset+int64(len(notice.Text))]) != notice.Text {
+			t.Errorf("Test %d: expected %q at file offset %d", i, notice.Text, notice.FileOffset)
+		}
+	}
+}
@@ -0,0 +1,539 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Command gotagger finds copyright notices in files and directory trees
+// and tags text with parts of speech.
+//
+//	gotagger [-corpus FILE] scan [-format text|jsonl|csv] [-binary]
+//		[-comments] [-archives=false] [-sensitivity high|medium|low]
+//		[-rev REV] [-workers N] [-unordered] PATH...
+//	gotagger [-corpus FILE] extract [FILE...]
+//	gotagger [-corpus FILE] tag [FILE...]
+//
+// scan walks every PATH and prints each notice with its file, span,
+// marker, years and holders. Binary files are skipped unless -binary is
+// given, then the strings of ELF, PE and Mach-O files are scanned.
+// -comments only looks in the comments of source files. The files in tar,
+// zip and jar archives and their compressed forms are scanned too, named
+// like vendor.tar.gz!/lib/foo.c, unless -archives=false is given.
+// With -rev each PATH is a git repository and the files of its tree at
+// REV are scanned without checking it out, they are named REV:path.
+// Files are scanned by -workers at once, files with the same contents
+// only once, and printed in the order they were found unless -unordered.
+// extract prints what Extract returns for each file and tag prints every
+// sentence as word|~|tag pairs, both read standard input without files.
+//
+// The corpus the tagger is built from is -corpus, $GOTAGGER_CORPUS or
+// CopyrightCorpus.in in the working directory.
+//
+// Like grep the exit code is 0 when notices were found, 1 when none were
+// and 2 when something went wrong.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	tagger "github.com/EKnapik/goTagger"
+)
+
+// exit codes
+const (
+	exitFound    = 0
+	exitNotFound = 1
+	exitError    = 2
+)
+
+// directories of version control systems are never walked
+var skipDirs = map[string]bool{
+	".git": true,
+	".hg":  true,
+	".svn": true,
+	".bzr": true,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// Runs gotagger with the arguments and returns the exit code
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("gotagger", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	corpus := flags.String("corpus", defaultCorpus(), "the tagged corpus the tagger is built from")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "usage: gotagger [-corpus FILE] scan|extract|tag [flags] [PATH...]\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+	if flags.NArg() < 1 {
+		flags.Usage()
+		return exitError
+	}
+
+	cmd := &command{stdin: stdin, stdout: stdout, stderr: stderr}
+	var runCommand func([]string) int
+	switch flags.Arg(0) {
+	case "scan":
+		runCommand = cmd.scan
+	case "extract":
+		runCommand = cmd.extract
+	case "tag":
+		runCommand = cmd.tag
+	default:
+		fmt.Fprintf(stderr, "gotagger: unknown command %q\n", flags.Arg(0))
+		flags.Usage()
+		return exitError
+	}
+
+	// New panics on a corpus it can not read
+	if _, err := os.Stat(*corpus); err != nil {
+		fmt.Fprintf(stderr, "gotagger: corpus: %v\n", err)
+		return exitError
+	}
+	cmd.tagger = tagger.New(*corpus)
+	return runCommand(flags.Args()[1:])
+}
+
+// Returns $GOTAGGER_CORPUS or CopyrightCorpus.in
+func defaultCorpus() string {
+	if corpus := os.Getenv("GOTAGGER_CORPUS"); corpus != "" {
+		return corpus
+	}
+	return "CopyrightCorpus.in"
+}
+
+// What every subcommand shares
+type command struct {
+	tagger *tagger.Tagger
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	failed bool
+}
+
+// Reports an error and remembers something went wrong
+func (cmd *command) errorf(format string, args ...interface{}) {
+	fmt.Fprintf(cmd.stderr, "gotagger: "+format+"\n", args...)
+	cmd.failed = true
+}
+
+// Returns the exit code for the files that were looked at
+func (cmd *command) exitCode(found bool) int {
+	switch {
+	case cmd.failed:
+		return exitError
+	case found:
+		return exitFound
+	}
+	return exitNotFound
+}
+
+// gotagger scan
+func (cmd *command) scan(args []string) int {
+	flags := flag.NewFlagSet("scan", flag.ContinueOnError)
+	flags.SetOutput(cmd.stderr)
+	format := flags.String("format", "text", "how notices are printed: text, jsonl or csv")
+	binary := flags.Bool("binary", false, "scan the strings of ELF, PE and Mach-O files")
+	comments := flags.Bool("comments", false, "only look in the comments of source files")
+	archives := flags.Bool("archives", true, "scan the files inside tar, zip and jar archives")
+	rev := flags.String("rev", "", "scan the git repository at each path at this revision")
+	workers := flags.Int("workers", runtime.NumCPU(), "how many files are scanned at once")
+	unordered := flags.Bool("unordered", false, "print notices as files finish instead of in order")
+	sensitivity := flags.String("sensitivity", "high", "which notices are kept: high, medium or low")
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+	if flags.NArg() < 1 {
+		cmd.errorf("scan needs at least one path")
+		return exitError
+	}
+
+	switch *sensitivity {
+	case "high":
+		cmd.tagger.SetSensitivity(tagger.SensitivityHigh)
+	case "medium":
+		cmd.tagger.SetSensitivity(tagger.SensitivityMedium)
+	case "low":
+		cmd.tagger.SetSensitivity(tagger.SensitivityLow)
+	default:
+		cmd.errorf("unknown sensitivity %q", *sensitivity)
+		return exitError
+	}
+	out, err := newFindingWriter(*format, cmd.stdout)
+	if err != nil {
+		cmd.errorf("%v", err)
+		return exitError
+	}
+
+	found := false
+	scanner := cmd.tagger.NewParallelScanner()
+	scanner.Workers = *workers
+	scanner.Ordered = !*unordered
+	scanner.Binary = *binary
+	scanner.Comments = *comments
+	err = scanner.Scan(context.Background(), func(add func(path string, data []byte) error) error {
+		for _, root := range flags.Args() {
+			var err error
+			if *rev != "" {
+				err = cmd.scanGit(root, *rev, *archives, add)
+			} else {
+				err = cmd.scanTree(root, *archives, add)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}, func(result tagger.ScanResult) error {
+		for _, finding := range resultFindings(result) {
+			found = true
+			if err := out.write(finding); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		cmd.errorf("%v", err)
+	}
+	if err := out.flush(); err != nil {
+		cmd.errorf("%v", err)
+	}
+	return cmd.exitCode(found)
+}
+
+// Scans every file under root, errors reading files are reported and
+// the scan goes on, an error from report stops it
+func (cmd *command) scanTree(root string, archives bool, report func(path string, data []byte) error) error {
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			cmd.errorf("%v", err)
+			return nil
+		}
+		if entry.IsDir() {
+			if skipDirs[entry.Name()] && path != root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		if archives && tagger.IsArchive(path) {
+			file, err := os.Open(path)
+			if err != nil {
+				cmd.errorf("%v", err)
+				return nil
+			}
+			defer file.Close()
+			return cmd.scanArchive(path, file, report)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			cmd.errorf("%v", err)
+			return nil
+		}
+		return report(path, data)
+	})
+}
+
+// Scans the files of the git repository at root at the revision, they
+// are named REV:path the way git show takes them
+func (cmd *command) scanGit(root string, rev string, archives bool, report func(path string, data []byte) error) error {
+	repo, err := tagger.OpenGitRepository(root)
+	if err != nil {
+		cmd.errorf("%v", err)
+		return nil
+	}
+	defer repo.Close()
+
+	var reportErr error
+	err = repo.WalkTree(rev, func(path string, blob string, data []byte) error {
+		path = rev + ":" + path
+		if archives && tagger.IsArchive(path) {
+			reportErr = cmd.scanArchive(path, bytes.NewReader(data), report)
+		} else {
+			reportErr = report(path, data)
+		}
+		return reportErr
+	})
+	if reportErr != nil {
+		return reportErr
+	}
+	if err != nil {
+		cmd.errorf("%s: %v", root, err)
+	}
+	return nil
+}
+
+// Scans the files in the archive, an archive that can not be read is an
+// error but the scan goes on
+func (cmd *command) scanArchive(path string, in io.Reader, report func(path string, data []byte) error) error {
+	var reportErr error
+	err := tagger.WalkArchive(path, in, tagger.DefaultArchiveLimits, func(memberPath string, data []byte) error {
+		reportErr = report(memberPath, data)
+		return reportErr
+	})
+	if reportErr != nil {
+		return reportErr
+	}
+	if err != nil {
+		cmd.errorf("%v", err)
+	}
+	return nil
+}
+
+// gotagger extract
+func (cmd *command) extract(args []string) int {
+	found := false
+	cmd.eachInput(args, func(name string, data []byte) {
+		notice := cmd.tagger.ExtractEncoded(data)
+		if notice == "" {
+			return
+		}
+		found = true
+		fmt.Fprintf(cmd.stdout, "%s: %s\n", name, notice)
+	})
+	return cmd.exitCode(found)
+}
+
+// gotagger tag
+func (cmd *command) tag(args []string) int {
+	tagged := false
+	tagInput := func(in io.Reader) {
+		reader := cmd.tagger.TagReader(in)
+		for reader.Next() {
+			tagged = true
+			words := make([]string, 0, len(reader.Sentence()))
+			for _, word := range reader.Sentence() {
+				words = append(words, word.String())
+			}
+			fmt.Fprintln(cmd.stdout, strings.Join(words, " "))
+		}
+		if err := reader.Err(); err != nil {
+			cmd.errorf("%v", err)
+		}
+	}
+
+	if len(args) == 0 {
+		tagInput(cmd.stdin)
+		return cmd.exitCode(tagged)
+	}
+	for _, path := range args {
+		file, err := os.Open(path)
+		if err != nil {
+			cmd.errorf("%v", err)
+			continue
+		}
+		tagInput(file)
+		file.Close()
+	}
+	return cmd.exitCode(tagged)
+}
+
+// Calls do with the contents of every file, or of standard input when
+// there are none
+func (cmd *command) eachInput(paths []string, do func(name string, data []byte)) {
+	if len(paths) == 0 {
+		data, err := io.ReadAll(cmd.stdin)
+		if err != nil {
+			cmd.errorf("%v", err)
+			return
+		}
+		do("-", data)
+		return
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			cmd.errorf("%v", err)
+			continue
+		}
+		do(path, data)
+	}
+}
+
+// One notice found in one file, what scan prints
+type finding struct {
+	File      string   `json:"file"`
+	Start     int64    `json:"start"`
+	End       int64    `json:"end"`
+	Line      int      `json:"line,omitempty"`
+	Column    int      `json:"column,omitempty"`
+	EndLine   int      `json:"end_line,omitempty"`
+	EndColumn int      `json:"end_column,omitempty"`
+	Section   string   `json:"section,omitempty"` // for binaries
+	Marker    string   `json:"marker"`
+	Years     []int    `json:"years"`
+	Holders   []string `json:"holders"`
+	Text      string   `json:"text"`
+}
+
+// Returns the findings of one scanned file, notices in compressed
+// sections of binaries are left out as they have no offset in the file
+func resultFindings(result tagger.ScanResult) []finding {
+	var findings = make([]finding, 0, len(result.Notices))
+	for _, notice := range result.Notices {
+		found := mkFinding(result.Path, notice)
+		found.Start, found.End = int64(notice.Start), int64(notice.End)
+		found.Line, found.Column = notice.StartPos.Line, notice.StartPos.Column
+		found.EndLine, found.EndColumn = notice.EndPos.Line, notice.EndPos.Column
+		findings = append(findings, found)
+	}
+	for _, notice := range result.Binary {
+		if notice.FileOffset < 0 {
+			continue
+		}
+		found := mkFinding(result.Path, notice.Notice)
+		found.Start = notice.FileOffset
+		found.End = notice.FileOffset + int64(notice.End-notice.Start)
+		found.Section = notice.Section
+		findings = append(findings, found)
+	}
+	return findings
+}
+
+// The parts of a finding every notice has
+func mkFinding(path string, notice tagger.Notice) finding {
+	return finding{
+		File:    path,
+		Marker:  notice.Marker,
+		Years:   notice.Years,
+		Holders: notice.Holders,
+		Text:    notice.Text,
+	}
+}
+
+// Prints findings in one of the output formats
+type findingWriter interface {
+	write(found finding) error
+	flush() error
+}
+
+// Returns the writer for the format
+func newFindingWriter(format string, out io.Writer) (findingWriter, error) {
+	switch format {
+	case "text":
+		return &textWriter{out: out}, nil
+	case "jsonl":
+		return &jsonWriter{encoder: json.NewEncoder(out)}, nil
+	case "csv":
+		// the header goes out even when nothing is found
+		writer := &csvWriter{out: csv.NewWriter(out)}
+		return writer, writer.out.Write(csvHeader)
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// file:line:column: text, the way compilers report, with the holders and
+// years after it
+type textWriter struct {
+	out io.Writer
+}
+
+func (writer *textWriter) write(found finding) error {
+	where := fmt.Sprintf("%s:%d:%d", found.File, found.Line, found.Column)
+	if found.Section != "" {
+		where = fmt.Sprintf("%s:%s+%d", found.File, found.Section, found.Start)
+	}
+	_, err := fmt.Fprintf(writer.out, "%s: %s\tholders=%q years=%s\n", where,
+		strings.Join(strings.Fields(found.Text), " "), strings.Join(found.Holders, "; "), joinYears(found.Years))
+	return err
+}
+
+func (writer *textWriter) flush() error {
+	return nil
+}
+
+// one JSON object a line
+type jsonWriter struct {
+	encoder *json.Encoder
+}
+
+func (writer *jsonWriter) write(found finding) error {
+	return writer.encoder.Encode(found)
+}
+
+func (writer *jsonWriter) flush() error {
+	return nil
+}
+
+// CSV with a header row, the years are space separated and the holders
+// separated by "; "
+type csvWriter struct {
+	out *csv.Writer
+}
+
+// the columns of the csv format
+var csvHeader = []string{"file", "start", "end", "line", "column", "end_line", "end_column",
+	"section", "marker", "years", "holders", "text"}
+
+func (writer *csvWriter) write(found finding) error {
+	return writer.out.Write([]string{
+		found.File,
+		strconv.FormatInt(found.Start, 10),
+		strconv.FormatInt(found.End, 10),
+		strconv.Itoa(found.Line),
+		strconv.Itoa(found.Column),
+		strconv.Itoa(found.EndLine),
+		strconv.Itoa(found.EndColumn),
+		found.Section,
+		found.Marker,
+		joinYears(found.Years),
+		strings.Join(found.Holders, "; "),
+		found.Text,
+	})
+}
+
+func (writer *csvWriter) flush() error {
+	writer.out.Flush()
+	return writer.out.Error()
+}
+
+// Returns the years separated by spaces
+func joinYears(years []int) string {
+	var strs = make([]string, 0, len(years))
+	for _, year := range years {
+		strs = append(strs, strconv.Itoa(year))
+	}
+	return strings.Join(strs, " ")
+}
@@ -0,0 +1,216 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Unit tests for the gotagger command
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testCorpus = "../../CopyrightCorpus.in"
+
+// writes the files into a new temporary directory
+func writeTree(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, contents := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// runs gotagger and returns the exit code and what it printed
+func runTagger(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(append([]string{"-corpus", testCorpus}, args...), strings.NewReader(""), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestScan(t *testing.T) {
+	type ScanTest struct {
+		Code     int
+		Contains []string
+		Args     []string
+	}
+
+	dir := writeTree(t, map[string]string{
+		"src/main.c":     "// Copyright 2010 Jane Doe\nint main() { return 0; }\n",
+		"src/empty.txt":  "nothing here\n",
+		"image.bin":      "\x00\x01Copyright 2010 Hidden Holder\x00",
+		".git/HEAD":      "Copyright 2010 Not Scanned\n",
+		"docs/readme.md": "(c) 2015 Eric Knapik\n",
+	})
+
+	tests := []ScanTest{
+		{
+			Code:     exitFound,
+			Contains: []string{"main.c:1:4: Copyright 2010 Jane Doe", "readme.md:1:1:"},
+			Args:     []string{"scan", dir},
+		},
+		{
+			Code:     exitFound,
+			Contains: []string{`"file":`, `"line":1`, `"holders":["Jane Doe"]`},
+			Args:     []string{"scan", "-format", "jsonl", filepath.Join(dir, "src")},
+		},
+		{
+			Code:     exitFound,
+			Contains: []string{"file,start,end,line", ",2010,Jane Doe,"},
+			Args:     []string{"scan", "-format", "csv", filepath.Join(dir, "src")},
+		},
+		{
+			Code: exitNotFound,
+			Args: []string{"scan", filepath.Join(dir, "src", "empty.txt")},
+		},
+		{
+			// the header is written even with nothing found
+			Code:     exitNotFound,
+			Contains: []string{"file,start,end,line,column,end_line,end_column,section,marker,years,holders,text\n"},
+			Args:     []string{"scan", "-format", "csv", filepath.Join(dir, "src", "empty.txt")},
+		},
+		{
+			Code: exitError,
+			Args: []string{"scan", filepath.Join(dir, "missing")},
+		},
+		{
+			Code: exitError,
+			Args: []string{"scan", "-format", "xml", dir},
+		},
+	}
+
+	for i, test := range tests {
+		code, stdout, stderr := runTagger(test.Args...)
+		if code != test.Code {
+			t.Errorf("Test %d: expected exit code %d got %d: %s", i, test.Code, code, stderr)
+		}
+		for _, want := range test.Contains {
+			if !strings.Contains(stdout, want) {
+				t.Errorf("Test %d: expected %q in %q", i, want, stdout)
+			}
+		}
+		if strings.Contains(stdout, "Hidden Holder") || strings.Contains(stdout, "Not Scanned") {
+			t.Errorf("Test %d: binary files and .git should be skipped got %q", i, stdout)
+		}
+	}
+
+	// the same files in the same order however many workers scan them
+	_, sequential, _ := runTagger("scan", "-workers", "1", dir)
+	_, parallel, _ := runTagger("scan", "-workers", "8", dir)
+	if sequential != parallel {
+		t.Errorf("expected the same output from 1 and 8 workers got %q and %q", sequential, parallel)
+	}
+
+	_, stdout, _ := runTagger("scan", "-format", "jsonl", filepath.Join(dir, "src", "main.c"))
+	var found finding
+	if err := json.Unmarshal([]byte(strings.TrimSpace(stdout)), &found); err != nil {
+		t.Fatalf("expected one JSON line got %q: %v", stdout, err)
+	}
+	if found.Text != "Copyright 2010 Jane Doe" || found.Start != 3 || found.End != 26 {
+		t.Errorf("expected the notice at [3, 26] got %+v", found)
+	}
+}
+
+func TestScanArchive(t *testing.T) {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	contents, _ := writer.Create("lib/foo.c")
+	contents.Write([]byte("// Copyright 2010 Jane Doe\n"))
+	writer.Close()
+	dir := writeTree(t, map[string]string{"vendor.jar": buf.String(), "broken.zip": "not a zip"})
+
+	code, stdout, stderr := runTagger("scan", dir)
+	if code != exitError || !strings.Contains(stdout, "vendor.jar!/lib/foo.c:1:4: Copyright 2010 Jane Doe") {
+		t.Errorf("expected the notice in the jar and an error for the broken zip got %d %q", code, stdout)
+	}
+	if !strings.Contains(stderr, "broken.zip") {
+		t.Errorf("expected an error for broken.zip got %q", stderr)
+	}
+
+	code, stdout, _ = runTagger("scan", "-archives=false", filepath.Join(dir, "vendor.jar"))
+	if code != exitNotFound || stdout != "" {
+		t.Errorf("expected the jar skipped as binary got %d %q", code, stdout)
+	}
+}
+
+func TestScanGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("no git command")
+	}
+	dir := writeTree(t, map[string]string{"lib/foo.c": "// Copyright 2010 Jane Doe\n"})
+	for _, args := range [][]string{{"init", "-q"}, {"add", "."}, {"commit", "-q", "-m", "one"}, {"tag", "v1"}} {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=T", "-c", "user.email=t@t"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Skipf("git %v: %v %s", args, err, out)
+		}
+	}
+	os.Remove(filepath.Join(dir, "lib", "foo.c"))
+
+	code, stdout, stderr := runTagger("scan", "-rev", "v1", dir)
+	if code != exitFound || !strings.Contains(stdout, "v1:lib/foo.c:1:4: Copyright 2010 Jane Doe") {
+		t.Errorf("expected the notice at v1 got %d %q %q", code, stdout, stderr)
+	}
+	if code, _, _ := runTagger("scan", "-rev", "v2", dir); code != exitError {
+		t.Errorf("expected exit code %d for an unknown revision got %d", exitError, code)
+	}
+}
+
+func TestExtractAndTag(t *testing.T) {
+	dir := writeTree(t, map[string]string{"a.txt": "Copyright 2010 Jane Doe\n"})
+
+	code, stdout, _ := runTagger("extract", filepath.Join(dir, "a.txt"))
+	if code != exitFound || !strings.Contains(stdout, "Copyright 2010 Jane Doe") {
+		t.Errorf("expected the notice got %d %q", code, stdout)
+	}
+
+	code, stdout, _ = runTagger("tag", filepath.Join(dir, "a.txt"))
+	if code != exitFound || !strings.HasPrefix(stdout, "Copyright|~|") || strings.Count(stdout, "\n") != 1 {
+		t.Errorf("expected one tagged sentence got %d %q", code, stdout)
+	}
+
+	if code, _, _ := runTagger("frobnicate"); code != exitError {
+		t.Errorf("expected exit code %d for an unknown command got %d", exitError, code)
+	}
+	var stderr bytes.Buffer
+	if code := run([]string{"-corpus", "missing.in", "tag"}, strings.NewReader(""), &bytes.Buffer{}, &stderr); code != exitError {
+		t.Errorf("expected exit code %d for a missing corpus got %d", exitError, code)
+	}
+}
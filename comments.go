@@ -0,0 +1,457 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// This file is about finding the comments of a source file before looking
+// for notices in them, the first item of the TODO. A notice is nearly
+// always in a comment and a notice spread over several lines only reads
+// as one once the comment leaders, the // and # and * at the start of
+// each line, are taken out. The comment style is picked from the file
+// extension, the file name or the #! line. Every comment block keeps a
+// table of offsets so what is found in it can be reported at its offset
+// in the file, the same as Transcoded does in encoding.go.
+
+package tagger
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+)
+
+// How comments are written in one language
+type CommentStyle struct {
+	Name      string
+	Line      []string        // leaders of comments that run to the end of the line
+	ColumnOne []string        // leaders that are only a comment in the first column
+	Blocks    []CommentDelims // comments with an open and a close
+	Quotes    string          // quotes of strings a comment can not start in
+	Continue  string          // what starts the lines inside a block, * in C
+}
+
+// The open and close of a block comment
+type CommentDelims struct {
+	Open      string
+	Close     string
+	LineStart bool // only opens at the start of a line, like =begin in Ruby
+}
+
+var cStyle = &CommentStyle{
+	Name:     "c",
+	Line:     []string{"//"},
+	Blocks:   []CommentDelims{{Open: "/*", Close: "*/"}},
+	Quotes:   "\"'",
+	Continue: "*",
+}
+
+var goStyle = &CommentStyle{
+	Name:     "go",
+	Line:     []string{"//"},
+	Blocks:   []CommentDelims{{Open: "/*", Close: "*/"}},
+	Quotes:   "\"'`",
+	Continue: "*",
+}
+
+var pythonStyle = &CommentStyle{
+	Name:   "python",
+	Line:   []string{"#"},
+	Blocks: []CommentDelims{{Open: `"""`, Close: `"""`}, {Open: "'''", Close: "'''"}},
+	Quotes: "\"'",
+}
+
+var shellStyle = &CommentStyle{
+	Name:   "shell",
+	Line:   []string{"#"},
+	Quotes: "\"'",
+}
+
+var rubyStyle = &CommentStyle{
+	Name:   "ruby",
+	Line:   []string{"#"},
+	Blocks: []CommentDelims{{Open: "=begin", Close: "=end", LineStart: true}},
+	Quotes: "\"'",
+}
+
+var sqlStyle = &CommentStyle{
+	Name:     "sql",
+	Line:     []string{"--"},
+	Blocks:   []CommentDelims{{Open: "/*", Close: "*/"}},
+	Quotes:   "'",
+	Continue: "*",
+}
+
+var htmlStyle = &CommentStyle{
+	Name:   "html",
+	Blocks: []CommentDelims{{Open: "<!--", Close: "-->"}},
+}
+
+var lispStyle = &CommentStyle{
+	Name:   "lisp",
+	Line:   []string{";"},
+	Blocks: []CommentDelims{{Open: "#|", Close: "|#"}},
+	Quotes: "\"",
+}
+
+var luaStyle = &CommentStyle{
+	Name:   "lua",
+	Line:   []string{"--"},
+	Blocks: []CommentDelims{{Open: "--[[", Close: "]]"}},
+	Quotes: "\"'",
+}
+
+var haskellStyle = &CommentStyle{
+	Name:   "haskell",
+	Line:   []string{"--"},
+	Blocks: []CommentDelims{{Open: "{-", Close: "-}"}},
+}
+
+var roffStyle = &CommentStyle{
+	Name: "roff",
+	Line: []string{`.\"`, `'\"`, `.\#`, `\"`, `\#`},
+}
+
+// fixed form Fortran, a C or * in the first column is a comment
+var fortranStyle = &CommentStyle{
+	Name:      "fortran",
+	Line:      []string{"!"},
+	ColumnOne: []string{"C", "c", "*"},
+	Quotes:    "\"'",
+}
+
+// free form Fortran only has !
+var fortran90Style = &CommentStyle{
+	Name:   "fortran90",
+	Line:   []string{"!"},
+	Quotes: "\"'",
+}
+
+// comment styles by file extension
+var commentExtensions = map[string]*CommentStyle{
+	".c": cStyle, ".h": cStyle, ".cc": cStyle, ".cpp": cStyle, ".cxx": cStyle,
+	".hh": cStyle, ".hpp": cStyle, ".hxx": cStyle, ".m": cStyle, ".mm": cStyle,
+	".java": cStyle, ".js": cStyle, ".mjs": cStyle, ".ts": cStyle, ".cs": cStyle,
+	".swift": cStyle, ".kt": cStyle, ".scala": cStyle, ".rs": cStyle,
+	".php": cStyle, ".css": cStyle, ".scss": cStyle, ".groovy": cStyle,
+	".go": goStyle, ".py": pythonStyle, ".pyw": pythonStyle,
+	".sh": shellStyle, ".bash": shellStyle, ".zsh": shellStyle, ".ksh": shellStyle,
+	".pl": shellStyle, ".pm": shellStyle, ".mk": shellStyle, ".cmake": shellStyle,
+	".yml": shellStyle, ".yaml": shellStyle, ".toml": shellStyle, ".r": shellStyle,
+	".tcl": shellStyle, ".awk": shellStyle, ".sed": shellStyle,
+	".rb": rubyStyle, ".rake": rubyStyle, ".gemspec": rubyStyle,
+	".sql": sqlStyle, ".lua": luaStyle, ".hs": haskellStyle,
+	".html": htmlStyle, ".htm": htmlStyle, ".xhtml": htmlStyle, ".xml": htmlStyle,
+	".xsl": htmlStyle, ".xsd": htmlStyle, ".svg": htmlStyle, ".plist": htmlStyle,
+	".lisp": lispStyle, ".lsp": lispStyle, ".cl": lispStyle, ".el": lispStyle,
+	".scm": lispStyle, ".ss": lispStyle, ".clj": lispStyle,
+	".roff": roffStyle, ".man": roffStyle, ".ms": roffStyle, ".me": roffStyle,
+	".1": roffStyle, ".2": roffStyle, ".3": roffStyle,
+	".4": roffStyle, ".5": roffStyle, ".6": roffStyle, ".7": roffStyle,
+	".8": roffStyle, ".9": roffStyle,
+	".f": fortranStyle, ".for": fortranStyle, ".f77": fortranStyle,
+	".f90": fortran90Style, ".f95": fortran90Style, ".f03": fortran90Style, ".f08": fortran90Style,
+}
+
+// comment styles of files known by their whole name
+var commentFileNames = map[string]*CommentStyle{
+	"makefile":       shellStyle,
+	"gnumakefile":    shellStyle,
+	"dockerfile":     shellStyle,
+	"cmakelists.txt": shellStyle,
+	"rakefile":       rubyStyle,
+	"gemfile":        rubyStyle,
+}
+
+// comment styles by the interpreter named on the #! line
+var commentInterpreters = map[string]*CommentStyle{
+	"sh":     shellStyle,
+	"bash":   shellStyle,
+	"zsh":    shellStyle,
+	"ksh":    shellStyle,
+	"dash":   shellStyle,
+	"perl":   shellStyle,
+	"awk":    shellStyle,
+	"tclsh":  shellStyle,
+	"python": pythonStyle,
+	"ruby":   rubyStyle,
+	"lua":    luaStyle,
+	"node":   cStyle,
+	"guile":  lispStyle,
+	"sbcl":   lispStyle,
+}
+
+// One comment of the input with its delimiters and leaders taken out
+type CommentBlock struct {
+	Start int    `json:"start"` // byte offset of the comment, its opener included
+	End   int    `json:"end"`   // byte offset just past the comment
+	Text  string `json:"text"`  // the comment, one line of it per line
+	// offsets[i] is the byte offset in the input of Text[i]
+	offsets []int
+	// the Text as it is built, Text is set from it once the block is done
+	text []byte
+	// made of line comments so the next line can carry on the block
+	line bool
+}
+
+// Given a byte offset into the Text of the block this returns the same
+// byte offset in the input. Offsets past the Text are the End.
+func (block *CommentBlock) Offset(textOffset int) int {
+	if textOffset < 0 {
+		return block.Start
+	}
+	if textOffset >= len(block.offsets) {
+		return block.End
+	}
+	return block.offsets[textOffset]
+}
+
+// adds some bytes of the input to the Text
+func (block *CommentBlock) add(text []byte, rawOffset int) {
+	block.text = append(block.text, text...)
+	for i := range text {
+		block.offsets = append(block.offsets, rawOffset+i)
+	}
+}
+
+// Returns the comment style of a file from its name or its #! line,
+// nil when it is not known
+func CommentStyleFor(path string, content []byte) *CommentStyle {
+	base := strings.ToLower(filepath.Base(path))
+	if style, ok := commentFileNames[base]; ok {
+		return style
+	}
+	if style, ok := commentExtensions[filepath.Ext(base)]; ok {
+		return style
+	}
+
+	if !bytes.HasPrefix(content, []byte("#!")) {
+		return nil
+	}
+	firstLine := content[2:]
+	if end := bytes.IndexByte(firstLine, '\n'); end >= 0 {
+		firstLine = firstLine[:end]
+	}
+	fields := strings.Fields(string(firstLine))
+	for len(fields) > 0 {
+		interpreter := filepath.Base(fields[0])
+		fields = fields[1:]
+		if interpreter == "env" || strings.HasPrefix(interpreter, "-") {
+			continue
+		}
+		// python3.8 is python
+		return commentInterpreters[strings.TrimRight(interpreter, "0123456789.")]
+	}
+	return nil
+}
+
+// Given the comment style of the input this returns every comment in it in
+// order. Line comments on lines right after each other are one block.
+func ExtractComments(style *CommentStyle, inBytes []byte) []CommentBlock {
+	var blocks = make([]CommentBlock, 0)
+
+	lineStart := 0
+	for i := 0; i < len(inBytes); {
+		if inBytes[i] == '\n' {
+			i++
+			lineStart = i
+			continue
+		}
+
+		if delims, ok := style.blockAt(inBytes, i, lineStart); ok {
+			blocks = append(blocks, style.mkBlockComment(inBytes, i, delims))
+			i = blocks[len(blocks)-1].End
+			continue
+		}
+
+		if leader, ok := style.lineAt(inBytes, i, lineStart); ok {
+			lineEnd := bytes.IndexByte(inBytes[i:], '\n')
+			if lineEnd < 0 {
+				lineEnd = len(inBytes)
+			} else {
+				lineEnd += i
+			}
+			text, textStart := stripLeader(inBytes[i:lineEnd], i, leader)
+
+			last := len(blocks) - 1
+			if last >= 0 && blocks[last].line && bytes.Count(inBytes[blocks[last].End:i], []byte("\n")) == 1 &&
+				len(bytes.TrimSpace(inBytes[blocks[last].End:i])) == 0 {
+				// carry on the block from the line before
+				blocks[last].add([]byte("\n"), blocks[last].End)
+			} else {
+				blocks = append(blocks, CommentBlock{Start: i, line: true})
+				last = len(blocks) - 1
+			}
+			blocks[last].add(text, textStart)
+			blocks[last].End = lineEnd
+			i = lineEnd
+			continue
+		}
+
+		if strings.IndexByte(style.Quotes, inBytes[i]) >= 0 {
+			i = skipString(inBytes, i)
+			continue
+		}
+		i++
+	}
+
+	for i := range blocks {
+		blocks[i].Text = strings.TrimRight(string(blocks[i].text), " \t\r\n")
+		blocks[i].text = nil
+	}
+	return blocks
+}
+
+// Returns the delimiters of the block comment that opens at offset i
+func (style *CommentStyle) blockAt(inBytes []byte, i int, lineStart int) (CommentDelims, bool) {
+	for _, delims := range style.Blocks {
+		if bytes.HasPrefix(inBytes[i:], []byte(delims.Open)) && (!delims.LineStart || i == lineStart) {
+			return delims, true
+		}
+	}
+	return CommentDelims{}, false
+}
+
+// Returns the leader of the line comment that starts at offset i
+func (style *CommentStyle) lineAt(inBytes []byte, i int, lineStart int) (string, bool) {
+	for _, leader := range style.Line {
+		if bytes.HasPrefix(inBytes[i:], []byte(leader)) {
+			return leader, true
+		}
+	}
+	if i == lineStart {
+		for _, leader := range style.ColumnOne {
+			if bytes.HasPrefix(inBytes[i:], []byte(leader)) {
+				return leader, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Builds the block of a comment opened at offset start, an unclosed
+// comment runs to the end of the input
+func (style *CommentStyle) mkBlockComment(inBytes []byte, start int, delims CommentDelims) CommentBlock {
+	block := CommentBlock{Start: start}
+	bodyStart := start + len(delims.Open)
+	bodyEnd := len(inBytes)
+	block.End = len(inBytes)
+	if at := bytes.Index(inBytes[bodyStart:], []byte(delims.Close)); at >= 0 {
+		bodyEnd = bodyStart + at
+		block.End = bodyEnd + len(delims.Close)
+	}
+
+	for lineStart := bodyStart; lineStart <= bodyEnd; {
+		lineEnd := bytes.IndexByte(inBytes[lineStart:bodyEnd], '\n')
+		if lineEnd < 0 {
+			lineEnd = bodyEnd
+		} else {
+			lineEnd += lineStart
+		}
+		text, textStart := stripLeader(inBytes[lineStart:lineEnd], lineStart, style.Continue)
+		// blank lines at the top of the comment are dropped
+		if len(block.offsets) > 0 {
+			block.add([]byte("\n"), lineStart-1)
+		}
+		if len(block.offsets) > 0 || len(text) > 0 {
+			block.add(text, textStart)
+		}
+		lineStart = lineEnd + 1
+	}
+	return block
+}
+
+// Takes the white space, the leader repeated any number of times and the
+// white space after it off the front of a comment line and the white space
+// off the end. Returns what is left and its offset in the input.
+func stripLeader(line []byte, offset int, leader string) ([]byte, int) {
+	trimmed := bytes.TrimLeft(line, " \t")
+	if leader != "" && bytes.HasPrefix(trimmed, []byte(leader)) {
+		trimmed = trimmed[len(leader):]
+		// ///, ## and ;;; but not the C of a Fortran comment
+		if repeat := leader[len(leader)-1:]; !isLetter(repeat[0]) {
+			trimmed = bytes.TrimLeft(trimmed, repeat)
+		}
+		trimmed = bytes.TrimLeft(trimmed, " \t")
+	}
+	offset += len(line) - len(trimmed)
+	return bytes.TrimRight(trimmed, " \t\r"), offset
+}
+
+// returns true for an ASCII letter
+func isLetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+// Returns the offset just past the string that starts at offset i. Only
+// back quoted strings go over more than one line.
+func skipString(inBytes []byte, i int) int {
+	quote := inBytes[i]
+	for j := i + 1; j < len(inBytes); j++ {
+		switch {
+		case inBytes[j] == '\\' && quote != '`':
+			j++
+		case inBytes[j] == quote:
+			return j + 1
+		case inBytes[j] == '\n' && quote != '`':
+			return j
+		}
+	}
+	return len(inBytes)
+}
+
+// Given the path and contents of a source file this returns the notices in
+// its comments. The offsets of each notice are offsets into the file but
+// its Text is the comment text, with the leaders of a notice spread over
+// several lines taken out. Files with no known comment style are searched
+// as a whole.
+func (copyrightTagger *Tagger) ExtractCommentNotices(path string, inBytes []byte) []Notice {
+	style := CommentStyleFor(path, inBytes)
+	if style == nil {
+		return copyrightTagger.ExtractNotices(inBytes)
+	}
+
+	var notices = make([]Notice, 0)
+	for _, block := range ExtractComments(style, inBytes) {
+		for _, notice := range copyrightTagger.ExtractNotices([]byte(block.Text)) {
+			notice.Start, notice.End = block.mapSpan(notice.Start, notice.End)
+			for i := range notice.Words {
+				notice.Words[i].Start, notice.Words[i].End = block.mapSpan(notice.Words[i].Start, notice.Words[i].End)
+			}
+			notices = append(notices, notice)
+		}
+	}
+	setPositions(inBytes, notices)
+	return notices
+}
+
+// maps a span of the Text to the input, the end is mapped from the last
+// byte of the span since the byte after it may be on another line
+func (block *CommentBlock) mapSpan(start int, end int) (int, int) {
+	if end <= start {
+		return block.Offset(start), block.Offset(start)
+	}
+	return block.Offset(start), block.Offset(end-1) + 1
+}
@@ -0,0 +1,156 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Unit tests for the comment lexer
+
+package tagger
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCommentStyleFor(t *testing.T) {
+	tests := map[string]string{
+		"main.go":        "go",
+		"src/Foo.JAVA":   "c",
+		"setup.py":       "python",
+		"Makefile":       "shell",
+		"query.sql":      "sql",
+		"index.html":     "html",
+		"init.el":        "lisp",
+		"conf.lua":       "lua",
+		"Main.hs":        "haskell",
+		"ls.1":           "roff",
+		"solver.f":       "fortran",
+		"solver.f90":     "fortran90",
+		"lib/thing.rb":   "ruby",
+		"no-extension":   "python",
+		"also-nothing":   "shell",
+		"README.unknown": "",
+	}
+	content := map[string]string{
+		"no-extension": "#!/usr/bin/env python3.8\nprint()\n",
+		"also-nothing": "#!/bin/bash -e\necho\n",
+	}
+
+	for path, expected := range tests {
+		style := CommentStyleFor(path, []byte(content[path]))
+		name := ""
+		if style != nil {
+			name = style.Name
+		}
+		if name != expected {
+			t.Errorf("%s: expected style %q got %q", path, expected, name)
+		}
+	}
+}
+
+func TestExtractComments(t *testing.T) {
+	type CommentTest struct {
+		Expected []string // the text of each block
+		Style    *CommentStyle
+		Text     string
+	}
+
+	tests := []CommentTest{
+		{
+			Expected: []string{"Copyright (C) 1999 Free Software\nFoundation, Inc.", "not a \"// comment\""},
+			Style:    cStyle,
+			Text:     "/*\n * Copyright (C) 1999 Free Software\n *    Foundation, Inc.\n */\nchar *s = \"// no\"; /* not a \"// comment\" */",
+		},
+		{
+			Expected: []string{"Copyright 2015 Eric Knapik\nAll rights reserved.", "one more"},
+			Style:    goStyle,
+			Text:     "// Copyright 2015 Eric Knapik\n//   All rights reserved.\n\npackage x // one more\ns := `// raw`",
+		},
+		{
+			Expected: []string{"!/bin/sh", "Copyright 2001 Acme", "trailing"},
+			Style:    shellStyle,
+			Text:     "#!/bin/sh\n\n## Copyright 2001 Acme\necho '# quoted' # trailing\n",
+		},
+		{
+			Expected: []string{"Copyright 2004 Foo", "block"},
+			Style:    rubyStyle,
+			Text:     "# Copyright 2004 Foo\nx = 1\n=begin\nblock\n=end\n",
+		},
+		{
+			Expected: []string{"Copyright 1999\nAcme"},
+			Style:    htmlStyle,
+			Text:     "<p>don't</p><!-- Copyright 1999\n     Acme -->",
+		},
+		{
+			Expected: []string{"Copyright 1989 PKWARE", "two"},
+			Style:    fortranStyle,
+			Text:     "C Copyright 1989 PKWARE\n      call x ! two\n",
+		},
+		{
+			Expected: []string{"Copyright 2011 Lua.org", "block"},
+			Style:    luaStyle,
+			Text:     "-- Copyright 2011 Lua.org\nlocal x = 1\n--[[ block ]]",
+		},
+		{
+			Expected: []string{"Copyright 2003 Someone"},
+			Style:    roffStyle,
+			Text:     ".\\\" Copyright 2003 Someone\n.TH LS 1\n",
+		},
+	}
+
+	for i, test := range tests {
+		blocks := ExtractComments(test.Style, []byte(test.Text))
+		var got []string
+		for _, block := range blocks {
+			got = append(got, block.Text)
+			// every byte of the text is the same byte of the input
+			for j := 0; j < len(block.Text); j++ {
+				if block.Text[j] != '\n' && test.Text[block.Offset(j)] != block.Text[j] {
+					t.Errorf("Test %d: byte %d of %q is not at offset %d", i, j, block.Text, block.Offset(j))
+					break
+				}
+			}
+		}
+		if !reflect.DeepEqual(got, test.Expected) {
+			t.Errorf("Test %d: expected %q got %q", i, test.Expected, got)
+		}
+	}
+}
+
+func TestExtractCommentNotices(t *testing.T) {
+	text := "/*\n * Copyright (c) 2007, 2008 Alastair\n *   Houghton\n */\nint copyright = 2010; /* Copyright nothing */\n"
+
+	notices := copyrightTagger.ExtractCommentNotices("houghton.c", []byte(text))
+	if len(notices) != 1 {
+		t.Fatalf("expected 1 notice got %d", len(notices))
+	}
+	if notices[0].Text != "Copyright (c) 2007, 2008 Alastair\nHoughton" {
+		t.Errorf("expected the leaders to be gone got %q", notices[0].Text)
+	}
+	if text[notices[0].Start:notices[0].End] != "Copyright (c) 2007, 2008 Alastair\n *   Houghton" {
+		t.Errorf("expected the offsets to be in the file got %q", text[notices[0].Start:notices[0].End])
+	}
+}
@@ -0,0 +1,308 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// This file is about getting raw input into UTF-8 before it is tagged.
+// The tagger and the copyright DFA only understand ASCII and UTF-8 so
+// files saved as Latin-1, Windows-1252 or UTF-16 need to be converted
+// first. While converting a table of offsets is kept so anything found
+// in the converted text can be reported at its offset in the raw input.
+
+package tagger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// The character encoding raw input was detected to be in
+type Encoding int
+
+const (
+	UTF8        Encoding = iota // UTF-8, which includes plain ASCII
+	UTF16LE                     // UTF-16 little endian
+	UTF16BE                     // UTF-16 big endian
+	Windows1252                 // Windows-1252, a superset of printable Latin-1
+)
+
+// how many bytes from the front of the input are looked at when guessing
+// if input without a byte order mark is UTF-16
+const utf16SampleSize int = 4096
+
+var (
+	utf8BOM    = []byte{0xEF, 0xBB, 0xBF}
+	utf16LEBOM = []byte{0xFF, 0xFE}
+	utf16BEBOM = []byte{0xFE, 0xFF}
+)
+
+// Windows-1252 only differs from Latin-1 in the 0x80 to 0x9F range.
+// The five bytes Windows-1252 leaves undefined are passed through as
+// the C1 control character of the same value, which is what Latin-1 does.
+var windows1252High = [32]rune{
+	'€', '\u0081', '‚', 'ƒ', '„', '…', '†', '‡',
+	'ˆ', '‰', 'Š', '‹', 'Œ', '\u008D', 'Ž', '\u008F',
+	'\u0090', '‘', '’', '“', '”', '•', '–', '—',
+	'˜', '™', 'š', '›', 'œ', '\u009D', 'ž', 'Ÿ',
+}
+
+func (enc Encoding) String() string {
+	switch enc {
+	case UTF8:
+		return "UTF-8"
+	case UTF16LE:
+		return "UTF-16LE"
+	case UTF16BE:
+		return "UTF-16BE"
+	case Windows1252:
+		return "Windows-1252"
+	default:
+		return "unknown"
+	}
+}
+
+// Transcoded holds raw input that was converted into UTF-8
+// along with what is needed to map offsets back to the raw input
+type Transcoded struct {
+	Text     []byte   // the input as UTF-8
+	Encoding Encoding // the encoding the raw input was detected as
+	// offsets[i] is the byte offset in the raw input of Text[i],
+	// it has one extra entry so the end of Text maps to the end of the input
+	offsets []int
+}
+
+// Given an offset into the converted Text this returns the matching
+// byte offset in the raw input. Offsets outside of Text are clamped.
+func (transcoded *Transcoded) Offset(textOffset int) int {
+	if textOffset < 0 {
+		return 0
+	}
+	if textOffset >= len(transcoded.offsets) {
+		return transcoded.offsets[len(transcoded.offsets)-1]
+	}
+	return transcoded.offsets[textOffset]
+}
+
+// Moves a position in the converted Text to the raw input. The Line and
+// Column16 count characters so they stay, the Offset and the Column are
+// bytes of the raw input.
+func (transcoded *Transcoded) position(pos Position) Position {
+	lineStart := transcoded.Offset(pos.Offset - pos.Column + 1)
+	pos.Offset = transcoded.Offset(pos.Offset)
+	pos.Column = pos.Offset - lineStart + 1
+	return pos
+}
+
+// Given raw bytes this will guess which encoding they are in.
+// A byte order mark always wins, then input that looks like UTF-16
+// (lots of zero bytes on one side of each pair), then UTF-8 if it is
+// valid and finally Windows-1252 for everything else.
+func DetectEncoding(raw []byte) Encoding {
+	switch {
+	case bytes.HasPrefix(raw, utf8BOM):
+		return UTF8
+	case bytes.HasPrefix(raw, utf16LEBOM):
+		return UTF16LE
+	case bytes.HasPrefix(raw, utf16BEBOM):
+		return UTF16BE
+	}
+
+	if enc, ok := guessUTF16(raw); ok {
+		return enc
+	}
+	if utf8.Valid(raw) {
+		return UTF8
+	}
+	return Windows1252
+}
+
+// Looks at the zero bytes in the front of the input. Text that is mostly
+// ASCII in UTF-16 has a zero in every other byte, on the odd side for
+// little endian and on the even side for big endian. Real UTF-8 and
+// Windows-1252 text almost never has zero bytes at all.
+func guessUTF16(raw []byte) (Encoding, bool) {
+	sample := raw
+	if len(sample) > utf16SampleSize {
+		sample = sample[:utf16SampleSize]
+	}
+	pairs := len(sample) / 2
+	if pairs < 2 {
+		return UTF8, false
+	}
+
+	var evenZeros, oddZeros int
+	for i := 0; i+1 < len(sample); i += 2 {
+		if sample[i] == 0 {
+			evenZeros++
+		}
+		if sample[i+1] == 0 {
+			oddZeros++
+		}
+	}
+
+	switch {
+	case oddZeros*10 >= pairs*4 && evenZeros*2 < oddZeros:
+		return UTF16LE, true
+	case evenZeros*10 >= pairs*4 && oddZeros*2 < evenZeros:
+		return UTF16BE, true
+	}
+	return UTF8, false
+}
+
+// Given raw bytes in any encoding DetectEncoding knows about this
+// returns them converted into UTF-8. A leading byte order mark is dropped
+// from the Text but still counted in the offsets.
+func Transcode(raw []byte) *Transcoded {
+	enc := DetectEncoding(raw)
+	transcoded := &Transcoded{Encoding: enc}
+
+	switch enc {
+	case UTF16LE, UTF16BE:
+		transcoded.fromUTF16(raw)
+	case Windows1252:
+		transcoded.fromWindows1252(raw)
+	default:
+		transcoded.fromUTF8(raw)
+	}
+	transcoded.offsets = append(transcoded.offsets, len(raw))
+
+	return transcoded
+}
+
+// UTF-8 input only needs its byte order mark removed
+func (transcoded *Transcoded) fromUTF8(raw []byte) {
+	start := 0
+	if bytes.HasPrefix(raw, utf8BOM) {
+		start = len(utf8BOM)
+	}
+	transcoded.Text = raw[start:]
+	transcoded.offsets = make([]int, 0, len(raw)-start+1)
+	for i := start; i < len(raw); i++ {
+		transcoded.offsets = append(transcoded.offsets, i)
+	}
+}
+
+// Every Windows-1252 byte is one character, anything at 0x80 or above
+// turns into two or three bytes of UTF-8 which all map back to that byte
+func (transcoded *Transcoded) fromWindows1252(raw []byte) {
+	transcoded.Text = make([]byte, 0, len(raw)+len(raw)/2)
+	transcoded.offsets = make([]int, 0, len(raw)+len(raw)/2+1)
+	for i, b := range raw {
+		var r rune = rune(b)
+		if b >= 0x80 && b < 0xA0 {
+			r = windows1252High[b-0x80]
+		}
+		transcoded.appendRune(r, i)
+	}
+}
+
+// UTF-16 is read one code unit at a time pairing up surrogates.
+// Broken surrogates and a dangling odd byte become U+FFFD.
+func (transcoded *Transcoded) fromUTF16(raw []byte) {
+	var order binary.ByteOrder = binary.LittleEndian
+	bom := utf16LEBOM
+	if transcoded.Encoding == UTF16BE {
+		order = binary.BigEndian
+		bom = utf16BEBOM
+	}
+
+	start := 0
+	if bytes.HasPrefix(raw, bom) {
+		start = len(bom)
+	}
+	transcoded.Text = make([]byte, 0, len(raw)-start)
+	transcoded.offsets = make([]int, 0, len(raw)-start+1)
+
+	for i := start; i < len(raw); {
+		if i+1 >= len(raw) {
+			transcoded.appendRune(utf8.RuneError, i)
+			break
+		}
+		unit := rune(order.Uint16(raw[i:]))
+		if utf16.IsSurrogate(unit) && i+3 < len(raw) {
+			pair := utf16.DecodeRune(unit, rune(order.Uint16(raw[i+2:])))
+			if pair != utf8.RuneError {
+				transcoded.appendRune(pair, i)
+				i += 4
+				continue
+			}
+		}
+		if utf16.IsSurrogate(unit) {
+			unit = utf8.RuneError
+		}
+		transcoded.appendRune(unit, i)
+		i += 2
+	}
+}
+
+// adds a single character to the Text remembering where it came from
+func (transcoded *Transcoded) appendRune(r rune, rawOffset int) {
+	before := len(transcoded.Text)
+	transcoded.Text = utf8.AppendRune(transcoded.Text, r)
+	for i := before; i < len(transcoded.Text); i++ {
+		transcoded.offsets = append(transcoded.offsets, rawOffset)
+	}
+}
+
+// The same as TagBytes except the input can be in any encoding
+// DetectEncoding understands. The byte offsets of the returned words
+// are offsets into rawBytes, not into the converted text.
+func (copyrightTagger *Tagger) TagBytesEncoded(rawBytes []byte) []TaggedWord {
+	transcoded := Transcode(rawBytes)
+	taggedWords := copyrightTagger.TagBytes(transcoded.Text)
+	for i := range taggedWords {
+		taggedWords[i].Start = transcoded.Offset(taggedWords[i].Start)
+		taggedWords[i].End = transcoded.Offset(taggedWords[i].End)
+	}
+	return taggedWords
+}
+
+// The same as Match except the input can be in any encoding
+// DetectEncoding understands
+func (copyrightTagger *Tagger) MatchEncoded(inBytes []byte) bool {
+	return copyrightTagger.Match(Transcode(inBytes).Text)
+}
+
+// The same as Extract except the input can be in any encoding
+// DetectEncoding understands, the notice is always returned as UTF-8
+func (copyrightTagger *Tagger) ExtractEncoded(inBytes []byte) string {
+	return copyrightTagger.Extract(Transcode(inBytes).Text)
+}
+
+// The same as FindAllIndex except the input can be in any encoding
+// DetectEncoding understands. The offsets returned are offsets into inBytes.
+func (copyrightTagger *Tagger) FindAllIndexEncoded(inBytes []byte) [][]int {
+	transcoded := Transcode(inBytes)
+	indicies := copyrightTagger.FindAllIndex(transcoded.Text)
+	for _, index := range indicies {
+		for i := range index {
+			index[i] = transcoded.Offset(index[i])
+		}
+	}
+	return indicies
+}
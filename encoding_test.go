@@ -0,0 +1,162 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Unit tests for detecting the input encoding and converting to UTF-8
+
+package tagger
+
+import (
+	"testing"
+	"unicode/utf16"
+)
+
+// encodes an ASCII/UTF-8 string as UTF-16 for building test input
+func mkUTF16(s string, bigEndian bool, bom bool) []byte {
+	var out []byte
+	units := utf16.Encode([]rune(s))
+	if bom {
+		units = append([]uint16{0xFEFF}, units...)
+	}
+	for _, unit := range units {
+		if bigEndian {
+			out = append(out, byte(unit>>8), byte(unit))
+		} else {
+			out = append(out, byte(unit), byte(unit>>8))
+		}
+	}
+	return out
+}
+
+func TestDetectEncoding(t *testing.T) {
+	type EncodingTest struct {
+		Expected Encoding
+		Raw      []byte
+	}
+
+	tests := []EncodingTest{
+		{Expected: UTF8, Raw: []byte("Copyright (c) 2007 Alastair Houghton")},
+		{Expected: UTF8, Raw: []byte("\xEF\xBB\xBF© 2001-2014 Python Software Foundation")},
+		{Expected: UTF8, Raw: []byte(" Â© 2001-2014 Python Software Foundation")},
+		{Expected: Windows1252, Raw: []byte("\xA9 2001-2014 Python Software Foundation")},
+		{Expected: Windows1252, Raw: []byte("Copyright \x93Acme\x94 2004")},
+		{Expected: UTF16LE, Raw: mkUTF16("© 2010 IBM", false, true)},
+		{Expected: UTF16BE, Raw: mkUTF16("© 2010 IBM", true, true)},
+		{Expected: UTF16LE, Raw: mkUTF16("Copyright 2010 IBM", false, false)},
+		{Expected: UTF16BE, Raw: mkUTF16("Copyright 2010 IBM", true, false)},
+		{Expected: UTF8, Raw: []byte{}},
+	}
+
+	for i, test := range tests {
+		r := DetectEncoding(test.Raw)
+		if r != test.Expected {
+			t.Errorf("Test %d: expected %v got %v", i, test.Expected, r)
+		}
+	}
+}
+
+func TestTranscode(t *testing.T) {
+	type TranscodeTest struct {
+		Expected string
+		Offsets  []int // raw offset of every byte in Expected plus the end
+		Raw      []byte
+	}
+
+	tests := []TranscodeTest{
+		{
+			Expected: "a©b",
+			Offsets:  []int{0, 1, 1, 2, 3},
+			Raw:      []byte("a\xA9b"),
+		},
+		{
+			Expected: "“x”",
+			Offsets:  []int{0, 0, 0, 1, 2, 2, 2, 3},
+			Raw:      []byte("\x93x\x94"),
+		},
+		{
+			Expected: "©1",
+			Offsets:  []int{3, 4, 5, 6},
+			Raw:      []byte("\xEF\xBB\xBF©1"),
+		},
+		{
+			Expected: "©1",
+			Offsets:  []int{2, 2, 4, 6},
+			Raw:      mkUTF16("©1", false, true),
+		},
+		{
+			Expected: "abc😀",
+			Offsets:  []int{0, 2, 4, 6, 6, 6, 6, 10},
+			Raw:      mkUTF16("abc😀", true, false),
+		},
+		{
+			Expected: "a�",
+			Offsets:  []int{2, 4, 4, 4, 5},
+			Raw:      []byte{0xFF, 0xFE, 'a', 0, 'b'},
+		},
+	}
+
+	for i, test := range tests {
+		r := Transcode(test.Raw)
+		if string(r.Text) != test.Expected {
+			t.Errorf("Test %d: expected %q got %q", i, test.Expected, r.Text)
+			continue
+		}
+		for j, expected := range test.Offsets {
+			if got := r.Offset(j); got != expected {
+				t.Errorf("Test %d: offset %d expected %d got %d", i, j, expected, got)
+			}
+		}
+	}
+}
+
+func TestFindAllIndexEncoded(t *testing.T) {
+	text := "It's an MIT-style license.  Here goes:\n" +
+		"\n" +
+		"Copyright (c) 2007, 2008 Alastair Houghton\n"
+
+	expected := copyrightTagger.FindAllIndex([]byte(text))
+	if len(expected) == 0 {
+		t.Fatalf("expected a notice in the UTF-8 text")
+	}
+
+	// every ASCII character is two bytes in UTF-16 and the BOM adds two more
+	matches := copyrightTagger.FindAllIndexEncoded(mkUTF16(text, false, true))
+	if len(matches) != len(expected) {
+		t.Fatalf("expected %d matches got %d", len(expected), len(matches))
+	}
+	for i := range matches {
+		for j := range matches[i] {
+			if matches[i][j] != expected[i][j]*2+2 {
+				t.Errorf("matches[%d][%d]: expected %d got %d", i, j, expected[i][j]*2+2, matches[i][j])
+			}
+		}
+	}
+
+	if !copyrightTagger.MatchEncoded([]byte("Copyright (c) 2007, 2008 Alastair Houghton \xA9")) {
+		t.Errorf("expected a match in Windows-1252 input")
+	}
+}
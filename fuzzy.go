@@ -0,0 +1,222 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// WriteUp item 3 of the TODO drops word similarity because working it
+// out for every word costs too much. Only a few words start a notice so
+// only those are checked, "Copyrigth", "Coypright" and the "C0pyright"
+// OCR makes are one edit away from copyright. A keyword is compiled once
+// into a DFA that accepts every word within maxKeywordEdits edits of it,
+// a swap of two letters next to each other counts as one edit. Checking
+// a word is then one table lookup per letter.
+
+package tagger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// how many edits a word may be from a keyword and still match it
+const maxKeywordEdits int = 1
+
+// keywords shorter than this match too many real words when misspelled
+const minFuzzyKeyword int = 5
+
+// real words close to a keyword that are not misspellings of it, the
+// plural is one edit from most keywords
+var fuzzyRealWords = map[string][]string{
+	"copyright": {"copyrights", "copyrighted", "copyrighting"},
+}
+
+// A keyword compiled into a DFA accepting the words close to it.
+// Letters of the keyword each have their own column in trans, every other
+// letter shares the last one. A negative state is dead, nothing that
+// follows can match.
+type fuzzyKeyword struct {
+	keyword string
+	classes map[rune]int
+	trans   [][]int
+	accept  []bool
+	// lower case words that never match, see fuzzyRealWords
+	real map[string]bool
+}
+
+// the misspellings of copyright the notice scanner tolerates
+var fuzzyCopyright = mustFuzzyKeyword("copyright")
+
+// Compiles the keyword into a DFA that matches words at most maxEdits
+// edits away, upper or lower case.
+// The DFA is built from the Levenshtein NFA by the subset construction.
+// A state of the NFA is how much of the keyword was matched, how many
+// edits were used and whether a swapped letter is still owed.
+func compileFuzzyKeyword(keyword string, maxEdits int) (*fuzzyKeyword, error) {
+	runes := []rune(keyword)
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+	if len(runes) < minFuzzyKeyword {
+		return nil, fmt.Errorf("fuzzy keyword %s is shorter than %d letters", keyword, minFuzzyKeyword)
+	}
+
+	fuzzy := &fuzzyKeyword{keyword: string(runes), classes: make(map[rune]int), real: make(map[string]bool)}
+	for _, word := range fuzzyRealWords[string(runes)] {
+		fuzzy.real[word] = true
+	}
+	for _, r := range runes {
+		if _, ok := fuzzy.classes[r]; !ok {
+			fuzzy.classes[r] = len(fuzzy.classes)
+		}
+	}
+	other := len(fuzzy.classes)
+	letter := make([]int, len(runes))
+	for i, r := range runes {
+		letter[i] = fuzzy.classes[r]
+	}
+
+	type nfaState struct {
+		matched, edits int
+		swapped        bool
+	}
+	// adds the state and every state reachable by dropping keyword letters
+	var closure func(set map[nfaState]bool, state nfaState)
+	closure = func(set map[nfaState]bool, state nfaState) {
+		if set[state] {
+			return
+		}
+		set[state] = true
+		if !state.swapped && state.edits < maxEdits && state.matched < len(runes) {
+			closure(set, nfaState{state.matched + 1, state.edits + 1, false})
+		}
+	}
+	key := func(set map[nfaState]bool) string {
+		var parts []string
+		for state := range set {
+			parts = append(parts, fmt.Sprint(state))
+		}
+		sort.Strings(parts)
+		return fmt.Sprint(parts)
+	}
+
+	var sets []map[nfaState]bool
+	index := make(map[string]int)
+	add := func(set map[nfaState]bool) int {
+		if len(set) == 0 {
+			return -1
+		}
+		k := key(set)
+		if i, ok := index[k]; ok {
+			return i
+		}
+		index[k] = len(sets)
+		sets = append(sets, set)
+		accept := false
+		for state := range set {
+			accept = accept || (state.matched == len(runes) && !state.swapped)
+		}
+		fuzzy.accept = append(fuzzy.accept, accept)
+		fuzzy.trans = append(fuzzy.trans, nil)
+		return index[k]
+	}
+
+	start := make(map[nfaState]bool)
+	closure(start, nfaState{})
+	add(start)
+	for current := 0; current < len(sets); current++ {
+		row := make([]int, other+1)
+		for class := range row {
+			next := make(map[nfaState]bool)
+			for state := range sets[current] {
+				i, edits := state.matched, state.edits
+				if state.swapped {
+					if letter[i] == class {
+						closure(next, nfaState{i + 2, edits, false})
+					}
+					continue
+				}
+				if i < len(runes) && letter[i] == class {
+					closure(next, nfaState{i + 1, edits, false})
+				}
+				if edits >= maxEdits {
+					continue
+				}
+				// an extra letter
+				closure(next, nfaState{i, edits + 1, false})
+				if i < len(runes) {
+					// a wrong letter
+					closure(next, nfaState{i + 1, edits + 1, false})
+				}
+				if i+1 < len(runes) && letter[i+1] == class && letter[i] != class {
+					// two letters swapped, the one owed comes next
+					closure(next, nfaState{i, edits + 1, true})
+				}
+			}
+			row[class] = add(next)
+		}
+		fuzzy.trans[current] = row
+	}
+	return fuzzy, nil
+}
+
+// compileFuzzyKeyword for keywords known to compile
+func mustFuzzyKeyword(keyword string) *fuzzyKeyword {
+	fuzzy, err := compileFuzzyKeyword(keyword, maxKeywordEdits)
+	if err != nil {
+		panic(err)
+	}
+	return fuzzy
+}
+
+// Returns true when the word is at most maxKeywordEdits edits from the
+// keyword, the keyword itself included, and is not a real word like
+// Copyrights
+func (fuzzy *fuzzyKeyword) Match(word string) bool {
+	if fuzzy.real[strings.ToLower(word)] {
+		return false
+	}
+	state := 0
+	other := len(fuzzy.classes)
+	for _, r := range word {
+		class, ok := fuzzy.classes[unicode.ToLower(r)]
+		if !ok {
+			class = other
+		}
+		state = fuzzy.trans[state][class]
+		if state < 0 {
+			return false
+		}
+	}
+	return fuzzy.accept[state]
+}
+
+// Returns true when the word is a misspelled copyright, copyright
+// itself is not
+func isFuzzyCopyright(word string) bool {
+	return fuzzyCopyright.Match(word) && !strings.EqualFold(word, fuzzyCopyright.keyword)
+}
@@ -0,0 +1,131 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Unit tests for matching misspelled keywords
+
+package tagger
+
+import (
+	"testing"
+)
+
+func TestFuzzyKeyword(t *testing.T) {
+	type FuzzyTest struct {
+		Expected bool
+		Text     string
+	}
+
+	tests := []FuzzyTest{
+		{Expected: true, Text: "copyright"},
+		{Expected: true, Text: "COPYRIGHT"},
+		{Expected: true, Text: "Copyrigth"}, // swapped
+		{Expected: true, Text: "Coypright"}, // swapped
+		{Expected: true, Text: "C0pyright"}, // wrong letter
+		{Expected: true, Text: "Copyrght"},  // missing letter
+		// real words one edit away are not misspellings
+		{Expected: false, Text: "Copyrights"},
+		{Expected: false, Text: "COPYRIGHTS"},
+		{Expected: true, Text: "Copywright"},
+		{Expected: false, Text: "Copyrighted"},
+		{Expected: false, Text: "Cpoyrigth"},
+		{Expected: false, Text: "copy"},
+		{Expected: false, Text: "right"},
+		{Expected: false, Text: ""},
+	}
+
+	for i, test := range tests {
+		if got := fuzzyCopyright.Match(test.Text); got != test.Expected {
+			t.Errorf("Test %d: expected %v for %q got %v", i, test.Expected, test.Text, got)
+		}
+	}
+
+	if _, err := compileFuzzyKeyword("copr", maxKeywordEdits); err == nil {
+		t.Errorf("expected an error for a keyword shorter than %d letters", minFuzzyKeyword)
+	}
+}
+
+func TestFuzzyNotices(t *testing.T) {
+	type FuzzyNoticeTest struct {
+		Marker  string
+		Fuzzy   bool
+		Holders []string
+		Text    string
+	}
+
+	tests := []FuzzyNoticeTest{
+		{
+			Marker:  "Copyrigth",
+			Fuzzy:   true,
+			Holders: []string{"Jane Doe"},
+			Text:    "Copyrigth 2010 Jane Doe",
+		},
+		{
+			Marker:  "Coypright",
+			Fuzzy:   true,
+			Holders: []string{"Jane Doe"},
+			Text:    "Coypright 2010 Jane Doe",
+		},
+		{
+			Marker:  "C0pyright",
+			Fuzzy:   true,
+			Holders: []string{"Jane Doe"},
+			Text:    "C0pyright 2010 Jane Doe",
+		},
+		{
+			Marker:  "Copyright",
+			Fuzzy:   false,
+			Holders: []string{"Jane Doe"},
+			Text:    "Copyright 2010 Jane Doe",
+		},
+		{
+			// a real word, not a misspelling
+			Text: "Copyrights 2010 Acme Inc.",
+		},
+	}
+
+	for i, test := range tests {
+		notices := copyrightTagger.ExtractNotices([]byte(test.Text))
+		if test.Marker == "" {
+			if len(notices) != 0 {
+				t.Errorf("Test %d: expected no notice in %q got %v", i, test.Text, notices)
+			}
+			continue
+		}
+		if len(notices) != 1 {
+			t.Errorf("Test %d: expected one notice in %q got %d", i, test.Text, len(notices))
+			continue
+		}
+		notice := notices[0]
+		if notice.Marker != test.Marker || notice.Fuzzy != test.Fuzzy {
+			t.Errorf("Test %d: expected marker %q fuzzy %v got %q %v", i, test.Marker, test.Fuzzy, notice.Marker, notice.Fuzzy)
+		}
+		if len(notice.Holders) != len(test.Holders) || (len(notice.Holders) > 0 && notice.Holders[0] != test.Holders[0]) {
+			t.Errorf("Test %d: expected holders %q got %q", i, test.Holders, notice.Holders)
+		}
+	}
+}
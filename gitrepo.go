@@ -0,0 +1,619 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Release reports need the notices at a tag without checking it out.
+// This reads a local .git directory with nothing but the standard library:
+// refs, packed-refs, loose objects and pack files with their deltas. A
+// revision is resolved to a commit, the commit to its tree, and the blobs
+// of the tree are walked. A blob with the same hash as one already walked
+// is the same file and is skipped.
+
+package tagger
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// the kinds of object in a pack file
+const (
+	packCommit   = 1
+	packTree     = 2
+	packBlob     = 3
+	packTag      = 4
+	packOfsDelta = 6
+	packRefDelta = 7
+)
+
+var packKinds = map[int]string{
+	packCommit: "commit",
+	packTree:   "tree",
+	packBlob:   "blob",
+	packTag:    "tag",
+}
+
+// the longest chain of tags, symbolic refs or deltas followed
+const maxGitChain int = 64
+
+// the largest object read, a pack claiming more is taken to be corrupt
+const maxGitObject int64 = 1 << 30
+
+// A local git repository opened for reading
+type GitRepository struct {
+	dir    string // the .git directory
+	common string // where objects and refs are, dir unless it is a worktree
+	packs  []*gitPack
+}
+
+// One pack file and its index
+type gitPack struct {
+	file    *os.File
+	fanout  [256]uint32
+	hashes  []byte // 20 bytes each, sorted
+	offsets []uint32
+	large   []byte // 8 byte offsets of objects past 2GB
+}
+
+// A notice found in a file of a git tree
+type GitNotice struct {
+	Notice
+	Path string `json:"path"` // the path of the file in the tree
+	Blob string `json:"blob"` // the hash of the file
+}
+
+// Opens the git repository at dir, the working tree or the .git
+// directory itself. Close it when done.
+func OpenGitRepository(dir string) (*GitRepository, error) {
+	gitDir, err := findGitDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	repo := &GitRepository{dir: gitDir, common: gitDir}
+	if common, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		repo.common = filepath.Join(gitDir, strings.TrimSpace(string(common)))
+	}
+
+	indexes, err := filepath.Glob(filepath.Join(repo.common, "objects", "pack", "*.idx"))
+	if err != nil {
+		return nil, err
+	}
+	for _, index := range indexes {
+		pack, err := openGitPack(index)
+		if err != nil {
+			repo.Close()
+			return nil, err
+		}
+		repo.packs = append(repo.packs, pack)
+	}
+	return repo, nil
+}
+
+// Returns the .git directory of dir, following a .git file to a worktree
+func findGitDir(dir string) (string, error) {
+	for _, gitDir := range []string{dir, filepath.Join(dir, ".git")} {
+		info, err := os.Stat(gitDir)
+		if err != nil {
+			continue
+		}
+		if !info.IsDir() {
+			// a worktree or submodule, .git names the real directory
+			link, err := os.ReadFile(gitDir)
+			if err != nil || !bytes.HasPrefix(link, []byte("gitdir:")) {
+				continue
+			}
+			target := strings.TrimSpace(string(link[len("gitdir:"):]))
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(dir, target)
+			}
+			gitDir = target
+		}
+		if _, err := os.Stat(filepath.Join(gitDir, "HEAD")); err == nil {
+			return gitDir, nil
+		}
+	}
+	return "", fmt.Errorf("%s: not a git repository", dir)
+}
+
+// Closes the pack files
+func (repo *GitRepository) Close() error {
+	var firstErr error
+	for _, pack := range repo.packs {
+		if err := pack.file.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	repo.packs = nil
+	return firstErr
+}
+
+// Returns the hash of the commit a revision names: a full hash, HEAD, a
+// branch, a tag or any other ref. Annotated tags are followed to what
+// they tag.
+func (repo *GitRepository) ResolveRevision(rev string) (string, error) {
+	hash, err := repo.resolveRef(rev)
+	if err != nil {
+		return "", err
+	}
+	for i := 0; i < maxGitChain; i++ {
+		kind, data, err := repo.ReadObject(hash)
+		if err != nil {
+			return "", err
+		}
+		if kind != "tag" {
+			return hash, nil
+		}
+		hash = gitHeader(data, "object")
+	}
+	return "", fmt.Errorf("%s: tags nested too deep", rev)
+}
+
+// Returns the hash a revision or ref names
+func (repo *GitRepository) resolveRef(rev string) (string, error) {
+	if isGitHash(rev) {
+		return strings.ToLower(rev), nil
+	}
+	candidates := []string{rev, "refs/" + rev, "refs/tags/" + rev, "refs/heads/" + rev,
+		"refs/remotes/" + rev, "refs/remotes/" + rev + "/HEAD"}
+	for _, ref := range candidates {
+		hash, ok, err := repo.readRef(ref, 0)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return hash, nil
+		}
+	}
+	return "", fmt.Errorf("%s: unknown revision", rev)
+}
+
+// Reads one ref from its file or packed-refs, following symbolic refs
+func (repo *GitRepository) readRef(ref string, depth int) (string, bool, error) {
+	if depth > maxGitChain {
+		return "", false, fmt.Errorf("%s: symbolic refs nested too deep", ref)
+	}
+	if strings.Contains(ref, "..") {
+		return "", false, nil
+	}
+	for _, dir := range []string{repo.dir, repo.common} {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(ref)))
+		if err != nil {
+			continue
+		}
+		line := strings.TrimSpace(string(data))
+		if strings.HasPrefix(line, "ref:") {
+			return repo.readRef(strings.TrimSpace(line[len("ref:"):]), depth+1)
+		}
+		if isGitHash(line) {
+			return line, true, nil
+		}
+	}
+
+	packed, err := os.ReadFile(filepath.Join(repo.common, "packed-refs"))
+	if err != nil {
+		return "", false, nil
+	}
+	for _, line := range strings.Split(string(packed), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[1] == ref && isGitHash(fields[0]) {
+			return fields[0], true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// Returns true for a full 40 digit hex object hash
+func isGitHash(text string) bool {
+	if len(text) != 40 {
+		return false
+	}
+	_, err := hex.DecodeString(text)
+	return err == nil
+}
+
+// Returns the value of a header line of a commit or tag, "tree" or
+// "object" for example
+func gitHeader(data []byte, name string) string {
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" {
+			break
+		}
+		if strings.HasPrefix(line, name+" ") {
+			return line[len(name)+1:]
+		}
+	}
+	return ""
+}
+
+// Returns the kind, "commit", "tree", "blob" or "tag", and the contents
+// of the object with the hash
+func (repo *GitRepository) ReadObject(hash string) (string, []byte, error) {
+	return repo.readObject(hash, 0)
+}
+
+// Reads the object, depth is how many deltas were followed to get to it
+func (repo *GitRepository) readObject(hash string, depth int) (string, []byte, error) {
+	hash = strings.ToLower(hash)
+	if !isGitHash(hash) {
+		return "", nil, fmt.Errorf("%q is not an object hash", hash)
+	}
+	if kind, data, err := repo.readLoose(hash); err == nil {
+		return kind, data, nil
+	} else if !os.IsNotExist(err) {
+		return "", nil, err
+	}
+
+	raw, _ := hex.DecodeString(hash)
+	for _, pack := range repo.packs {
+		if offset, ok := pack.find(raw); ok {
+			kind, data, err := repo.readPacked(pack, offset, depth)
+			if err != nil && depth > 0 {
+				// the object the delta chain started at is named
+				return "", nil, err
+			} else if err != nil {
+				return "", nil, fmt.Errorf("object %s: %v", hash, err)
+			}
+			return packKinds[kind], data, nil
+		}
+	}
+	return "", nil, fmt.Errorf("object %s not found", hash)
+}
+
+// Reads a loose object, "kind size\0contents" compressed with zlib
+func (repo *GitRepository) readLoose(hash string) (string, []byte, error) {
+	file, err := os.Open(filepath.Join(repo.common, "objects", hash[:2], hash[2:]))
+	if err != nil {
+		return "", nil, err
+	}
+	defer file.Close()
+
+	inflated, err := zlib.NewReader(file)
+	if err != nil {
+		return "", nil, fmt.Errorf("object %s: %v", hash, err)
+	}
+	defer inflated.Close()
+	data, err := io.ReadAll(inflated)
+	if err != nil {
+		return "", nil, fmt.Errorf("object %s: %v", hash, err)
+	}
+
+	nul := bytes.IndexByte(data, 0)
+	if nul < 0 {
+		return "", nil, fmt.Errorf("object %s: bad header", hash)
+	}
+	header := strings.Fields(string(data[:nul]))
+	if len(header) != 2 {
+		return "", nil, fmt.Errorf("object %s: bad header", hash)
+	}
+	size, err := strconv.Atoi(header[1])
+	if err != nil || size != len(data)-nul-1 {
+		return "", nil, fmt.Errorf("object %s: bad size", hash)
+	}
+	return header[0], data[nul+1:], nil
+}
+
+// Reads the index of a pack file, version 2, and opens the pack
+func openGitPack(indexPath string) (*gitPack, error) {
+	index, err := os.ReadFile(indexPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(index) < 8+256*4 || !bytes.Equal(index[:4], []byte("\377tOc")) || binary.BigEndian.Uint32(index[4:]) != 2 {
+		return nil, fmt.Errorf("%s: not a version 2 pack index", indexPath)
+	}
+
+	pack := &gitPack{}
+	for i := range pack.fanout {
+		pack.fanout[i] = binary.BigEndian.Uint32(index[8+4*i:])
+	}
+	count := int(pack.fanout[255])
+	at := 8 + 256*4
+	if len(index) < at+count*(20+4+4) {
+		return nil, fmt.Errorf("%s: index is cut short", indexPath)
+	}
+	pack.hashes = index[at : at+20*count]
+	at += 20*count + 4*count // the CRCs are not needed
+	pack.offsets = make([]uint32, count)
+	for i := range pack.offsets {
+		pack.offsets[i] = binary.BigEndian.Uint32(index[at+4*i:])
+	}
+	pack.large = index[at+4*count:]
+
+	pack.file, err = os.Open(strings.TrimSuffix(indexPath, ".idx") + ".pack")
+	if err != nil {
+		return nil, err
+	}
+	return pack, nil
+}
+
+// Returns the offset of the object in the pack
+func (pack *gitPack) find(hash []byte) (int64, bool) {
+	low := 0
+	if hash[0] > 0 {
+		low = int(pack.fanout[hash[0]-1])
+	}
+	high := int(pack.fanout[hash[0]])
+	i := low + sort.Search(high-low, func(i int) bool {
+		return bytes.Compare(pack.hashes[20*(low+i):20*(low+i)+20], hash) >= 0
+	})
+	if i >= high || !bytes.Equal(pack.hashes[20*i:20*i+20], hash) {
+		return 0, false
+	}
+	offset := pack.offsets[i]
+	if offset&0x80000000 == 0 {
+		return int64(offset), true
+	}
+	at := 8 * int(offset&0x7fffffff)
+	if at+8 > len(pack.large) {
+		return 0, false
+	}
+	return int64(binary.BigEndian.Uint64(pack.large[at:])), true
+}
+
+// Reads the object at the offset of the pack, applying deltas
+func (repo *GitRepository) readPacked(pack *gitPack, offset int64, depth int) (int, []byte, error) {
+	if depth > maxGitChain {
+		return 0, nil, fmt.Errorf("delta chain too long")
+	}
+	reader := bufio.NewReader(io.NewSectionReader(pack.file, offset, 1<<62))
+
+	b, err := reader.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	kind := int(b>>4) & 7
+	size := int64(b & 15)
+	for shift := 4; b&0x80 != 0; shift += 7 {
+		if shift > 63-7 {
+			return 0, nil, fmt.Errorf("object size too large")
+		}
+		if b, err = reader.ReadByte(); err != nil {
+			return 0, nil, err
+		}
+		size |= int64(b&0x7f) << shift
+	}
+	if size > maxGitObject {
+		return 0, nil, fmt.Errorf("object size %d too large", size)
+	}
+
+	var baseKind int
+	var base []byte
+	switch kind {
+	case packCommit, packTree, packBlob, packTag:
+	case packOfsDelta:
+		if b, err = reader.ReadByte(); err != nil {
+			return 0, nil, err
+		}
+		back := int64(b & 0x7f)
+		for b&0x80 != 0 {
+			if back > offset {
+				break
+			}
+			if b, err = reader.ReadByte(); err != nil {
+				return 0, nil, err
+			}
+			back = (back+1)<<7 | int64(b&0x7f)
+		}
+		if back <= 0 || back > offset {
+			return 0, nil, fmt.Errorf("delta base offset out of the pack")
+		}
+		baseKind, base, err = repo.readPacked(pack, offset-back, depth+1)
+	case packRefDelta:
+		var hash [20]byte
+		if _, err = io.ReadFull(reader, hash[:]); err != nil {
+			return 0, nil, err
+		}
+		var kindName string
+		kindName, base, err = repo.readObject(hex.EncodeToString(hash[:]), depth+1)
+		for number, name := range packKinds {
+			if name == kindName {
+				baseKind = number
+			}
+		}
+	default:
+		return 0, nil, fmt.Errorf("unknown object kind %d", kind)
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+
+	inflated, err := zlib.NewReader(reader)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer inflated.Close()
+	data := make([]byte, size)
+	if _, err := io.ReadFull(inflated, data); err != nil {
+		return 0, nil, err
+	}
+	if base == nil {
+		return kind, data, nil
+	}
+	data, err = applyGitDelta(base, data)
+	return baseKind, data, err
+}
+
+// Builds an object from its base and a delta, a run of copies out of the
+// base and inserts of new bytes
+func applyGitDelta(base []byte, delta []byte) ([]byte, error) {
+	varint := func() (int, bool) {
+		value, shift := 0, 0
+		for len(delta) > 0 && shift <= 63-7 {
+			b := delta[0]
+			delta = delta[1:]
+			value |= int(b&0x7f) << shift
+			shift += 7
+			if b&0x80 == 0 {
+				return value, true
+			}
+		}
+		return 0, false
+	}
+	baseSize, ok := varint()
+	if !ok || baseSize != len(base) {
+		return nil, fmt.Errorf("delta base size does not match")
+	}
+	size, ok := varint()
+	if !ok || int64(size) > maxGitObject {
+		return nil, fmt.Errorf("bad delta")
+	}
+
+	var data = make([]byte, 0, size)
+	for len(delta) > 0 {
+		op := delta[0]
+		delta = delta[1:]
+		if op&0x80 == 0 {
+			// insert the next op bytes
+			if op == 0 || int(op) > len(delta) {
+				return nil, fmt.Errorf("bad delta insert")
+			}
+			data = append(data, delta[:op]...)
+			delta = delta[op:]
+			continue
+		}
+		// copy, the bits of op say which bytes of offset and size follow
+		var offset, length int
+		for i := 0; i < 7; i++ {
+			if op&(1<<uint(i)) == 0 {
+				continue
+			}
+			if len(delta) == 0 {
+				return nil, fmt.Errorf("bad delta copy")
+			}
+			if i < 4 {
+				offset |= int(delta[0]) << (8 * uint(i))
+			} else {
+				length |= int(delta[0]) << (8 * uint(i-4))
+			}
+			delta = delta[1:]
+		}
+		if length == 0 {
+			length = 0x10000
+		}
+		if offset+length > len(base) {
+			return nil, fmt.Errorf("delta copy past the base")
+		}
+		data = append(data, base[offset:offset+length]...)
+	}
+	if len(data) != size {
+		return nil, fmt.Errorf("delta result size does not match")
+	}
+	return data, nil
+}
+
+// Calls walk with the path, hash and contents of every file in the tree
+// of the revision. A file with the same hash as one walked already is
+// skipped, so is anything that is not a regular file or executable.
+func (repo *GitRepository) WalkTree(rev string, walk func(path string, blob string, data []byte) error) error {
+	commit, err := repo.ResolveRevision(rev)
+	if err != nil {
+		return err
+	}
+	kind, data, err := repo.ReadObject(commit)
+	if err != nil {
+		return err
+	}
+	tree := commit
+	if kind == "commit" {
+		tree = gitHeader(data, "tree")
+	} else if kind != "tree" {
+		return fmt.Errorf("%s is a %s not a commit", rev, kind)
+	}
+	return repo.walkTree(tree, "", make(map[string]bool), walk)
+}
+
+// Walks one tree, prefix is its path
+func (repo *GitRepository) walkTree(tree string, prefix string, seen map[string]bool, walk func(string, string, []byte) error) error {
+	kind, data, err := repo.ReadObject(tree)
+	if err != nil {
+		return err
+	}
+	if kind != "tree" {
+		return fmt.Errorf("object %s is a %s not a tree", tree, kind)
+	}
+
+	// each entry is "mode name\0" and 20 bytes of hash
+	for len(data) > 0 {
+		nul := bytes.IndexByte(data, 0)
+		space := bytes.IndexByte(data, ' ')
+		if nul < 0 || space < 0 || space > nul || len(data) < nul+21 {
+			return fmt.Errorf("tree %s: bad entry", tree)
+		}
+		mode := string(data[:space])
+		name := string(data[space+1 : nul])
+		hash := hex.EncodeToString(data[nul+1 : nul+21])
+		data = data[nul+21:]
+		if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+			return fmt.Errorf("tree %s: bad name %q", tree, name)
+		}
+
+		entryPath := path.Join(prefix, name)
+		switch mode {
+		case "40000":
+			if err := repo.walkTree(hash, entryPath, seen, walk); err != nil {
+				return err
+			}
+		case "100644", "100755", "100664":
+			if seen[hash] {
+				continue
+			}
+			seen[hash] = true
+			_, blob, err := repo.ReadObject(hash)
+			if err != nil {
+				return err
+			}
+			if err := walk(entryPath, hash, blob); err != nil {
+				return err
+			}
+		}
+		// symbolic links and submodules have nothing to scan
+	}
+	return nil
+}
+
+// Finds the notices in the files of the tree of the revision
+func (copyrightTagger *Tagger) ExtractGitNotices(repo *GitRepository, rev string) ([]GitNotice, error) {
+	var notices = make([]GitNotice, 0)
+	err := repo.WalkTree(rev, func(filePath string, blob string, data []byte) error {
+		for _, notice := range copyrightTagger.ExtractNotices(data) {
+			notices = append(notices, GitNotice{Notice: notice, Path: filePath, Blob: blob})
+		}
+		return nil
+	})
+	return notices, err
+}
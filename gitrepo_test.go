@@ -0,0 +1,348 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Unit tests for reading git repositories
+
+package tagger
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// a git repository written by hand into a temporary directory
+type testRepo struct {
+	t   *testing.T
+	dir string // the .git directory
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	dir := filepath.Join(t.TempDir(), ".git")
+	for _, sub := range []string{"objects/pack", "refs/heads", "refs/tags"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	repo := &testRepo{t: t, dir: dir}
+	repo.write("HEAD", "ref: refs/heads/main\n")
+	return repo
+}
+
+func (repo *testRepo) write(name string, contents string) {
+	if err := os.WriteFile(filepath.Join(repo.dir, filepath.FromSlash(name)), []byte(contents), 0644); err != nil {
+		repo.t.Fatal(err)
+	}
+}
+
+func deflate(data []byte) []byte {
+	var buf bytes.Buffer
+	writer := zlib.NewWriter(&buf)
+	writer.Write(data)
+	writer.Close()
+	return buf.Bytes()
+}
+
+func gitHash(kind string, data []byte) []byte {
+	sum := sha1.Sum(append([]byte(fmt.Sprintf("%s %d\x00", kind, len(data))), data...))
+	return sum[:]
+}
+
+// writes a loose object and returns its hash
+func (repo *testRepo) loose(kind string, data []byte) string {
+	hash := hex.EncodeToString(gitHash(kind, data))
+	dir := filepath.Join(repo.dir, "objects", hash[:2])
+	os.MkdirAll(dir, 0755)
+	full := append([]byte(fmt.Sprintf("%s %d\x00", kind, len(data))), data...)
+	if err := os.WriteFile(filepath.Join(dir, hash[2:]), deflate(full), 0644); err != nil {
+		repo.t.Fatal(err)
+	}
+	return hash
+}
+
+// one entry of a tree object
+type treeEntry struct {
+	mode string
+	name string
+	hash string
+}
+
+func mkTree(entries []treeEntry) []byte {
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		raw, _ := hex.DecodeString(entry.hash)
+		fmt.Fprintf(&buf, "%s %s\x00", entry.mode, entry.name)
+		buf.Write(raw)
+	}
+	return buf.Bytes()
+}
+
+// writes a pack with the blob base and a second blob as a delta of it,
+// returns the two hashes
+func (repo *testRepo) pack(base []byte, delta []byte, target []byte) (string, string) {
+	objectHeader := func(kind int, size int) []byte {
+		header := []byte{byte(kind<<4) | byte(size&15)}
+		for size >>= 4; size > 0; size >>= 7 {
+			header[len(header)-1] |= 0x80
+			header = append(header, byte(size&0x7f))
+		}
+		return header
+	}
+
+	var pack bytes.Buffer
+	pack.WriteString("PACK")
+	binary.Write(&pack, binary.BigEndian, uint32(2))
+	binary.Write(&pack, binary.BigEndian, uint32(2))
+
+	baseOffset := pack.Len()
+	pack.Write(objectHeader(packBlob, len(base)))
+	pack.Write(deflate(base))
+
+	deltaOffset := pack.Len()
+	pack.Write(objectHeader(packOfsDelta, len(delta)))
+	back := deltaOffset - baseOffset
+	encoded := []byte{byte(back & 0x7f)}
+	for back >>= 7; back > 0; back >>= 7 {
+		back--
+		encoded = append([]byte{byte(0x80 | back&0x7f)}, encoded...)
+	}
+	pack.Write(encoded)
+	pack.Write(deflate(delta))
+	sum := sha1.Sum(pack.Bytes())
+	pack.Write(sum[:])
+
+	type object struct {
+		hash   []byte
+		offset int
+	}
+	objects := []object{{gitHash("blob", base), baseOffset}, {gitHash("blob", target), deltaOffset}}
+	sort.Slice(objects, func(i, j int) bool { return bytes.Compare(objects[i].hash, objects[j].hash) < 0 })
+
+	var index bytes.Buffer
+	index.WriteString("\377tOc")
+	binary.Write(&index, binary.BigEndian, uint32(2))
+	for i := 0; i < 256; i++ {
+		count := uint32(0)
+		for _, obj := range objects {
+			if int(obj.hash[0]) <= i {
+				count++
+			}
+		}
+		binary.Write(&index, binary.BigEndian, count)
+	}
+	for _, obj := range objects {
+		index.Write(obj.hash)
+	}
+	index.Write(make([]byte, 4*len(objects)))
+	for _, obj := range objects {
+		binary.Write(&index, binary.BigEndian, uint32(obj.offset))
+	}
+	index.Write(sum[:])
+	index.Write(make([]byte, 20))
+
+	repo.write("objects/pack/pack-test.pack", pack.String())
+	repo.write("objects/pack/pack-test.idx", index.String())
+	return hex.EncodeToString(gitHash("blob", base)), hex.EncodeToString(gitHash("blob", target))
+}
+
+func TestGitRepository(t *testing.T) {
+	repo := newTestRepo(t)
+
+	base := []byte("Copyright 2010 Jane Doe\nsome code\n")
+	target := []byte("Copyright 2010 Jane Doe\nmore code\n")
+	// copy the first 24 bytes of the base and insert "more code\n"
+	delta := append([]byte{byte(len(base)), byte(len(target)), 0x80 | 0x01 | 0x10, 0, 24, 10}, "more code\n"...)
+	baseHash, targetHash := repo.pack(base, delta, target)
+
+	lib := repo.loose("tree", mkTree([]treeEntry{{"100644", "a.c", baseHash}}))
+	root := repo.loose("tree", mkTree([]treeEntry{
+		{"100644", "b.c", targetHash},
+		{"100755", "copy.c", baseHash},
+		{"120000", "link", baseHash},
+		{"40000", "lib", lib},
+	}))
+	commit := repo.loose("commit", []byte("tree "+root+"\nauthor A <a@b> 0 +0000\n\nfirst\n"))
+	tag := repo.loose("tag", []byte("object "+commit+"\ntype commit\ntag v1.0\n\nrelease\n"))
+	repo.write("refs/heads/main", commit+"\n")
+	repo.write("packed-refs", "# pack-refs with: peeled\n"+tag+" refs/tags/v1.0\n")
+
+	opened, err := OpenGitRepository(filepath.Dir(repo.dir))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer opened.Close()
+
+	for _, rev := range []string{"HEAD", "main", "refs/heads/main", "v1.0", commit, strings.ToUpper(commit)} {
+		hash, err := opened.ResolveRevision(rev)
+		if err != nil || hash != commit {
+			t.Errorf("expected %s to resolve to %s got %s %v", rev, commit, hash, err)
+		}
+	}
+	if _, err := opened.ResolveRevision("v2.0"); err == nil {
+		t.Errorf("expected an error for an unknown revision")
+	}
+
+	var walked []string
+	err = opened.WalkTree("v1.0", func(path string, blob string, data []byte) error {
+		walked = append(walked, path)
+		if hex.EncodeToString(gitHash("blob", data)) != blob {
+			t.Errorf("%s: contents do not match hash %s", path, blob)
+		}
+		return nil
+	})
+	// lib/a.c is the same as copy.c and the link is not a file
+	if err != nil || strings.Join(walked, " ") != "b.c copy.c" {
+		t.Errorf("expected b.c copy.c got %q %v", walked, err)
+	}
+
+	notices, err := copyrightTagger.ExtractGitNotices(opened, "HEAD")
+	if err != nil || len(notices) != 2 {
+		t.Fatalf("expected 2 notices got %d %v", len(notices), err)
+	}
+	if notices[0].Path != "b.c" || notices[0].Blob != targetHash || !strings.HasPrefix(notices[0].Text, "Copyright 2010 Jane Doe") {
+		t.Errorf("expected Jane Doe in b.c got %q in %s", notices[0].Text, notices[0].Path)
+	}
+}
+
+func TestApplyGitDelta(t *testing.T) {
+	type DeltaTest struct {
+		Expected string // "" for an error
+		Delta    []byte
+	}
+
+	base := []byte("0123456789")
+	tests := []DeltaTest{
+		{Expected: "345abc", Delta: []byte{10, 6, 0x80 | 0x01 | 0x10, 3, 3, 3, 'a', 'b', 'c'}},
+		{Expected: "0123", Delta: []byte{10, 4, 0x80 | 0x10, 4}},
+		{Expected: "", Delta: []byte{9, 4, 0x80 | 0x10, 4}},                                           // wrong base size
+		{Expected: "", Delta: []byte{10, 4, 0x80 | 0x01 | 0x10, 8, 4}},                                // past the base
+		{Expected: "", Delta: []byte{10, 5, 0x80 | 0x10, 4}},                                          // wrong result size
+		{Expected: "", Delta: []byte{10, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}}, // huge result size
+		{Expected: "", Delta: []byte{10, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01}},
+	}
+
+	for i, test := range tests {
+		got, err := applyGitDelta(base, test.Delta)
+		if test.Expected == "" {
+			if err == nil {
+				t.Errorf("Test %d: expected an error got %q", i, got)
+			}
+			continue
+		}
+		if err != nil || string(got) != test.Expected {
+			t.Errorf("Test %d: expected %q got %q %v", i, test.Expected, got, err)
+		}
+	}
+}
+
+func TestReadPackedCorrupt(t *testing.T) {
+	type PackedTest struct {
+		Object []byte // the object at offset 12 of the pack
+	}
+
+	hash := bytes.Repeat([]byte{0xab}, 20)
+	tests := []PackedTest{
+		// a size with more than 63 bits
+		{Object: []byte{0xb0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}},
+		// larger than any object read
+		{Object: []byte{0xb0, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01}},
+		// an offset delta reaching back past the start of the pack
+		{Object: []byte{0x65, 0x7f}},
+		{Object: []byte{0x65, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}},
+		// a ref delta of itself
+		{Object: append([]byte{0x75}, hash...)},
+	}
+
+	for i, test := range tests {
+		repo := newTestRepo(t)
+		repo.write("objects/pack/pack-test.pack", "PACK\x00\x00\x00\x02\x00\x00\x00\x01"+string(test.Object))
+		opened, err := OpenGitRepository(filepath.Dir(repo.dir))
+		if err != nil {
+			t.Fatalf("Test %d: unexpected error %v", i, err)
+		}
+		pack := &gitPack{hashes: hash, offsets: []uint32{12}}
+		for at := int(hash[0]); at < 256; at++ {
+			pack.fanout[at] = 1
+		}
+		pack.file, err = os.Open(filepath.Join(repo.dir, "objects/pack/pack-test.pack"))
+		if err != nil {
+			t.Fatalf("Test %d: unexpected error %v", i, err)
+		}
+		opened.packs = append(opened.packs, pack)
+
+		if kind, data, err := opened.ReadObject(hex.EncodeToString(hash)); err == nil {
+			t.Errorf("Test %d: expected an error got %s %q", i, kind, data)
+		}
+		opened.Close()
+	}
+}
+
+// the same against a repository the git command made and packed
+func TestGitCommandRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("no git command")
+	}
+	dir := t.TempDir()
+	gitCmd := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=T", "-c", "user.email=t@t", "-c", "init.defaultBranch=main"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Skipf("git %v: %v %s", args, err, out)
+		}
+	}
+	gitCmd("init", "-q")
+	text := strings.Repeat("the quick brown fox jumps over the lazy dog\n", 50)
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("Copyright 2010 Jane Doe\n"+text), 0644)
+	gitCmd("add", ".")
+	gitCmd("commit", "-q", "-m", "one")
+	gitCmd("tag", "-a", "v1", "-m", "v1")
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("Copyright 2011 Jane Doe\n"+text), 0644)
+	gitCmd("commit", "-q", "-a", "-m", "two")
+	gitCmd("gc", "-q", "--aggressive")
+
+	repo, err := OpenGitRepository(dir)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer repo.Close()
+	for _, test := range []struct{ rev, year string }{{"v1", "2010"}, {"HEAD", "2011"}} {
+		notices, err := copyrightTagger.ExtractGitNotices(repo, test.rev)
+		if err != nil || len(notices) != 1 || !strings.Contains(notices[0].Text, test.year) {
+			t.Errorf("%s: expected the %s notice got %v %v", test.rev, test.year, notices, err)
+		}
+	}
+}
@@ -0,0 +1,699 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// This file is about the notice grammar, the DFA that decides which
+// tagged words make up a copyright notice. The DFA used to be a table
+// written out by hand in Go, now it is read from a small text file so new
+// states can be added without touching the code. The grammar the package
+// has always used is built in as defaultNoticeGrammar below.
+//
+// A grammar file is read line by line, anything after a # is a comment:
+//
+//	input NAME word=W tag=T contains=S fuzzy=F
+//		declares an input class. A tagged word is the first input whose
+//		matchers all hold: word is the lower case word, tag is the part of
+//		speech (tag= with nothing after it is a word that was not tagged),
+//		contains looks for S anywhere in the word and fuzzy takes words
+//		one edit away from F as well as F, see fuzzy.go. A word matching no
+//		input is the input "other".
+//	start STATE
+//		the state the DFA starts in
+//	begin STATE...
+//		states that begin a new notice, the words before are dropped
+//	lead STATE...
+//		begin states whose words stay with the notice when the next word
+//		begins one again, like Portions in "Portions Copyright"
+//	accept STATE...
+//		states where the words so far are a notice, these are final too
+//	reject STATE...
+//		states that are not part of a notice, moving into one ends
+//		whatever was being collected
+//	final STATE...
+//		states a notice may end in, see scanNotices in copyright.go
+//	state NAME
+//		declares a state, it is followed by its transitions, one per line,
+//		"INPUT -> STATE". "default -> STATE" is used for every input
+//		not listed. The state named * holds transitions for every state,
+//		they are used when a state does not list the input itself.
+//
+// When a grammar is loaded every state must have somewhere to go on every
+// input and every state must be reachable from the start state.
+
+package tagger
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// the input every word that matches no declared input becomes
+const otherInput string = "other"
+
+// the state holding transitions used by every state
+const anyState string = "*"
+
+// A compiled notice grammar ready to run over tagged words
+type NoticeGrammar struct {
+	states []string
+	inputs []grammarInput
+	// trans[state][input] is the next state, the last input is "other"
+	trans  [][]int
+	start  int
+	begin  []bool
+	lead   []bool
+	accept []bool
+	reject []bool
+	final  []bool
+}
+
+// One declared input class of a grammar
+type grammarInput struct {
+	name        string
+	word        string
+	tag         string
+	contains    string
+	hasWord     bool
+	hasTag      bool
+	hasContains bool
+	fuzzy       *fuzzyKeyword
+}
+
+// the notice DFA the package ships with
+const defaultNoticeGrammar string = `
+# The default copyright notice grammar.
+# A notice begins at the word copyright, at Copr., at the © symbol, at
+# Portions or at a left parenthesis that could be (c). It goes on through
+# numbers, proper nouns, commas, dashes and a few small words and is
+# accepted when a period or some other word follows a number or proper
+# noun. A trailing "All rights reserved." is part of the notice.
+
+input copyright word=copyright
+input copr      word=copr
+input coprdot   word=copr.      # the tagger often keeps the period
+input portions  word=portions
+input c         word=c tag=nn
+input lonec     word=c          # a c that is not a noun starts over
+input by        word=by
+input all       word=all
+input rights    word=rights
+input reserved  word=reserved
+input present   word=present    # the open end of 1999-present
+input apos      word='          # the apostrophe of '98
+input misspelt  fuzzy=copyright  # Copyrigth, C0pyright
+input csym      contains=©
+input lparen    tag=(
+input rparen    tag=)
+input cd        tag=cd
+input np        tag=np
+input dt        tag=dt
+input in        tag=in
+input dash      tag=--
+input comma     tag=,
+input period    tag=.
+input sym       tag=sym
+input cc        tag=cc
+input untagged  tag=            # words the tagger could not tag start over
+
+start  REJECT
+begin  START LPAREN CSYM COPR PORTIONS
+lead   START CSYM COPR PORTIONS
+accept ACCEPT RESERVED
+reject REJECT
+final  ACCEPT CD NP RESERVED
+
+state *
+	copyright -> START
+	misspelt  -> START
+	copr      -> COPR
+	coprdot   -> START
+	portions  -> PORTIONS
+	lonec     -> START
+	untagged  -> START
+	csym      -> CSYM
+
+# just saw the word copyright
+state START
+	lparen  -> LPARENC
+	cd      -> CD
+	apos    -> APOS
+	np      -> NP
+	dt      -> DT
+	all     -> DT
+	by      -> IN
+	sym     -> SYM
+	default -> REJECT
+
+# a left parenthesis with no copyright before it
+state LPAREN
+	c       -> CCHAR
+	lonec   -> CCHAR
+	default -> REJECT
+
+# the c of (c)
+state CCHAR
+	rparen  -> RPAREN
+	default -> REJECT
+
+# the end of (c)
+state RPAREN
+	cd      -> CD
+	apos    -> APOS
+	np      -> NP
+	dt      -> DT
+	all     -> DT
+	by      -> IN
+	default -> REJECT
+
+state NP
+	cd       -> CD
+	np       -> NP
+	dt       -> DT
+	all      -> ALL
+	in       -> IN
+	by       -> IN
+	dash     -> DASH
+	comma    -> COMMA
+	period   -> ACCEPT
+	sym      -> SYM
+	cc       -> OTHER
+	other    -> ACCEPT
+	rights   -> ACCEPT
+	reserved -> ACCEPT
+	default  -> REJECT
+
+state COMMA
+	cd      -> CD
+	apos    -> APOS
+	np      -> NP
+	dt      -> DT
+	all     -> ALL
+	default -> REJECT
+
+state CD
+	cd       -> CD
+	apos     -> APOS
+	np       -> NP
+	dt       -> DT
+	all      -> ALL
+	in       -> IN
+	by       -> IN
+	dash     -> DASH
+	comma    -> COMMA
+	period   -> ACCEPT
+	sym      -> SYM
+	cc       -> OTHER
+	other    -> ACCEPT
+	rights   -> ACCEPT
+	reserved -> ACCEPT
+	default  -> REJECT
+
+state DASH
+	cd      -> CD
+	apos    -> APOS
+	present -> CD
+	np      -> NP
+	dt      -> DT
+	all     -> DT
+	default -> REJECT
+
+state IN
+	np      -> NP
+	dt      -> DT
+	all     -> DT
+	cc      -> OTHER
+	default -> REJECT
+
+state DT
+	np      -> NP
+	cc      -> OTHER
+	default -> REJECT
+
+# more years and holders can follow an accepted notice
+state ACCEPT
+	csym    -> ACCEPT
+	cd      -> ACCEPT
+	np      -> ACCEPT
+	sym     -> ACCEPT
+	all     -> ALL
+	default -> REJECT
+
+state REJECT
+	lparen  -> LPAREN
+	default -> REJECT
+
+state SYM
+	rparen  -> RPAREN
+	cd      -> CD
+	np      -> NP
+	dash    -> DASH
+	default -> REJECT
+
+# a conjunction, and or or
+state OTHER
+	cd      -> CD
+	np      -> NP
+	dt      -> DT
+	all     -> DT
+	in      -> IN
+	by      -> IN
+	default -> REJECT
+
+# just saw the © symbol
+state CSYM
+	lparen  -> LPAREN
+	rparen  -> RPAREN
+	cd      -> CD
+	apos    -> APOS
+	np      -> NP
+	dt      -> DT
+	all     -> DT
+	in      -> IN
+	by      -> IN
+	dash    -> DASH
+	comma   -> COMMA
+	sym     -> SYM
+	cc      -> OTHER
+	default -> REJECT
+
+# a left parenthesis right after copyright
+state LPARENC
+	c       -> CCHAR
+	lonec   -> CCHAR
+	default -> REJECT
+
+# Copr. is short for copyright, the period is part of it
+state COPR
+	period  -> START
+	lparen  -> LPARENC
+	cd      -> CD
+	np      -> NP
+	default -> REJECT
+
+# an abbreviated year like '98 needs its number
+state APOS
+	cd      -> CD
+	default -> REJECT
+
+# Portions only starts a notice when copyright follows
+state PORTIONS
+	default -> REJECT
+
+# the words of "All rights reserved."
+state ALL
+	rights  -> RIGHTS
+	np      -> NP
+	cc      -> OTHER
+	default -> REJECT
+
+state RIGHTS
+	reserved -> RESERVED
+	default  -> REJECT
+
+state RESERVED
+	period  -> RESERVED
+	default -> REJECT
+`
+
+// Returns the grammar the package ships with. It is parsed every time
+// so callers are free to keep their own copy.
+func DefaultNoticeGrammar() *NoticeGrammar {
+	grammar, err := ParseNoticeGrammar(strings.NewReader(defaultNoticeGrammar))
+	if err != nil {
+		panic("the built in notice grammar is broken: " + err.Error())
+	}
+	return grammar
+}
+
+// Reads and validates a notice grammar from the given path
+func LoadNoticeGrammar(path string) (*NoticeGrammar, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseNoticeGrammar(file)
+}
+
+// the grammar as it is read, before it is checked and compiled
+type rawGrammar struct {
+	inputs      []grammarInput
+	inputIndex  map[string]int
+	states      []string
+	stateIndex  map[string]int
+	transitions map[string]map[string]string // state -> input -> state
+	roles       map[string][]string          // role -> states
+	lines       map[string]int               // where each state was declared
+}
+
+// Reads a notice grammar from any reader, checks it and compiles it
+func ParseNoticeGrammar(reader io.Reader) (*NoticeGrammar, error) {
+	raw := rawGrammar{
+		inputIndex:  make(map[string]int),
+		stateIndex:  make(map[string]int),
+		transitions: make(map[string]map[string]string),
+		roles:       make(map[string][]string),
+		lines:       make(map[string]int),
+	}
+
+	scanner := bufio.NewScanner(reader)
+	lineNum := 0
+	currState := ""
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if comment := strings.Index(line, "#"); comment >= 0 {
+			line = line[:comment]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		var err error
+		switch fields[0] {
+		case "input":
+			err = raw.addInput(fields[1:])
+			currState = ""
+		case "start", "begin", "lead", "accept", "reject", "final":
+			if len(fields) < 2 || (fields[0] == "start" && len(fields) != 2) {
+				err = fmt.Errorf("%s needs a state", fields[0])
+			}
+			raw.roles[fields[0]] = append(raw.roles[fields[0]], fields[1:]...)
+			currState = ""
+		case "state":
+			if len(fields) != 2 {
+				err = fmt.Errorf("state needs exactly one name")
+			} else if _, ok := raw.transitions[fields[1]]; ok {
+				err = fmt.Errorf("state %s is declared twice", fields[1])
+			} else {
+				currState = fields[1]
+				raw.transitions[currState] = make(map[string]string)
+				raw.lines[currState] = lineNum
+				if currState != anyState {
+					raw.stateIndex[currState] = len(raw.states)
+					raw.states = append(raw.states, currState)
+				}
+			}
+		default:
+			if currState == "" {
+				err = fmt.Errorf("unknown line %q", strings.TrimSpace(line))
+			} else {
+				err = raw.addTransition(currState, fields)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("notice grammar line %d: %v", lineNum, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return raw.compile()
+}
+
+// input NAME word=W tag=T contains=S fuzzy=F
+func (raw *rawGrammar) addInput(fields []string) error {
+	if len(fields) < 2 {
+		return fmt.Errorf("input needs a name and at least one matcher")
+	}
+	input := grammarInput{name: fields[0]}
+	if input.name == otherInput || input.name == "default" {
+		return fmt.Errorf("%s can not be declared as an input", input.name)
+	}
+	if _, ok := raw.inputIndex[input.name]; ok {
+		return fmt.Errorf("input %s is declared twice", input.name)
+	}
+
+	for _, matcher := range fields[1:] {
+		parts := strings.SplitN(matcher, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("matcher %q should be key=value", matcher)
+		}
+		switch parts[0] {
+		case "word":
+			input.word = strings.ToLower(parts[1])
+			input.hasWord = true
+		case "tag":
+			input.tag = parts[1]
+			input.hasTag = true
+		case "contains":
+			input.contains = parts[1]
+			input.hasContains = parts[1] != ""
+		case "fuzzy":
+			fuzzy, err := compileFuzzyKeyword(parts[1], maxKeywordEdits)
+			if err != nil {
+				return err
+			}
+			input.fuzzy = fuzzy
+		default:
+			return fmt.Errorf("unknown matcher %q", parts[0])
+		}
+	}
+
+	raw.inputIndex[input.name] = len(raw.inputs)
+	raw.inputs = append(raw.inputs, input)
+	return nil
+}
+
+// INPUT -> STATE, inside a state block
+func (raw *rawGrammar) addTransition(state string, fields []string) error {
+	if len(fields) != 3 || fields[1] != "->" {
+		return fmt.Errorf("expected INPUT -> STATE")
+	}
+	_, declared := raw.inputIndex[fields[0]]
+	if !declared && fields[0] != otherInput && fields[0] != "default" {
+		return fmt.Errorf("unknown input %s", fields[0])
+	}
+	if _, ok := raw.transitions[state][fields[0]]; ok {
+		return fmt.Errorf("state %s has two transitions on %s", state, fields[0])
+	}
+	raw.transitions[state][fields[0]] = fields[2]
+	return nil
+}
+
+// Checks the raw grammar for mistakes and builds the transition table
+func (raw *rawGrammar) compile() (*NoticeGrammar, error) {
+	if len(raw.states) == 0 {
+		return nil, fmt.Errorf("notice grammar has no states")
+	}
+	grammar := &NoticeGrammar{
+		states: raw.states,
+		inputs: raw.inputs,
+		trans:  make([][]int, len(raw.states)),
+		begin:  make([]bool, len(raw.states)),
+		lead:   make([]bool, len(raw.states)),
+		accept: make([]bool, len(raw.states)),
+		reject: make([]bool, len(raw.states)),
+		final:  make([]bool, len(raw.states)),
+	}
+
+	// every state named anywhere must be declared
+	for state, transitions := range raw.transitions {
+		for input, target := range transitions {
+			if _, ok := raw.stateIndex[target]; !ok {
+				return nil, fmt.Errorf("notice grammar: state %s goes to unknown state %s on %s", state, target, input)
+			}
+		}
+	}
+	roleStates := map[string][]bool{"begin": grammar.begin, "lead": grammar.lead, "accept": grammar.accept, "reject": grammar.reject, "final": grammar.final}
+	for role, states := range raw.roles {
+		for _, state := range states {
+			index, ok := raw.stateIndex[state]
+			if !ok {
+				return nil, fmt.Errorf("notice grammar: %s names unknown state %s", role, state)
+			}
+			if role == "start" {
+				grammar.start = index
+			} else {
+				roleStates[role][index] = true
+			}
+			if role == "accept" {
+				grammar.final[index] = true
+			}
+		}
+	}
+	if len(raw.roles["start"]) != 1 {
+		return nil, fmt.Errorf("notice grammar: needs exactly one start state")
+	}
+	if len(raw.roles["begin"]) == 0 {
+		return nil, fmt.Errorf("notice grammar: needs at least one begin state")
+	}
+	for _, state := range raw.roles["lead"] {
+		if !grammar.begin[raw.stateIndex[state]] {
+			return nil, fmt.Errorf("notice grammar: lead state %s is not a begin state", state)
+		}
+	}
+
+	// fill in the table, a state's own transitions win over the * state's
+	// and either of those win over a default
+	anyTrans := raw.transitions[anyState]
+	inputNames := append(make([]string, 0, len(raw.inputs)+1), otherInput)
+	for _, input := range raw.inputs {
+		inputNames = append(inputNames, input.name)
+	}
+	for index, state := range raw.states {
+		grammar.trans[index] = make([]int, len(raw.inputs)+1)
+		var missing []string
+		for _, input := range inputNames {
+			target, ok := raw.transitions[state][input]
+			if !ok {
+				target, ok = anyTrans[input]
+			}
+			if !ok {
+				target, ok = raw.transitions[state]["default"]
+			}
+			if !ok {
+				target, ok = anyTrans["default"]
+			}
+			if !ok {
+				missing = append(missing, input)
+				continue
+			}
+			column := len(raw.inputs)
+			if input != otherInput {
+				column = raw.inputIndex[input]
+			}
+			grammar.trans[index][column] = raw.stateIndex[target]
+		}
+		if len(missing) > 0 {
+			return nil, fmt.Errorf("notice grammar line %d: state %s has no transition on %s", raw.lines[state], state, strings.Join(missing, ", "))
+		}
+	}
+
+	// walk the table from the start state to find unreachable states
+	reached := make([]bool, len(raw.states))
+	reached[grammar.start] = true
+	queue := []int{grammar.start}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for _, next := range grammar.trans[state] {
+			if !reached[next] {
+				reached[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	var unreachable []string
+	for index, state := range raw.states {
+		if !reached[index] {
+			unreachable = append(unreachable, state)
+		}
+	}
+	if len(unreachable) > 0 {
+		sort.Strings(unreachable)
+		return nil, fmt.Errorf("notice grammar: unreachable states %s", strings.Join(unreachable, ", "))
+	}
+
+	return grammar, nil
+}
+
+// Returns the names of the states in the order they were declared
+func (grammar *NoticeGrammar) States() []string {
+	return append([]string(nil), grammar.states...)
+}
+
+// Returns the column of the transition table the tagged word falls in
+func (grammar *NoticeGrammar) classify(taggedWord TaggedWord) int {
+	lowerWord := strings.ToLower(taggedWord.Text)
+	for index, input := range grammar.inputs {
+		if input.hasWord && input.word != lowerWord {
+			continue
+		}
+		if input.hasTag && input.tag != taggedWord.Tag {
+			continue
+		}
+		if input.hasContains && !strings.Contains(taggedWord.Text, input.contains) {
+			continue
+		}
+		if input.fuzzy != nil && !input.fuzzy.Match(taggedWord.Text) {
+			continue
+		}
+		return index
+	}
+	return len(grammar.inputs)
+}
+
+// Given the current state and the next tagged word returns the next state
+func (grammar *NoticeGrammar) next(currentState int, taggedWord TaggedWord) int {
+	return grammar.trans[currentState][grammar.classify(taggedWord)]
+}
+
+// the words and tags the old CopyrightDFA table had keys for, X is any
+var (
+	legacyWords = []Tri{{word: "copyright", pos: "nn"}, {word: "c", pos: "nn"}, {word: "©", pos: "sym"}}
+	legacyTags  = []string{"(", ")", ",", "--", ".", "cc", "cd", "dt", "in", "np", "sym", "X"}
+)
+
+// Returns the grammar as the table the Tagger's CopyrightDFA used to hold,
+// with the same keys and the old state numbers, START to LPARENC. States
+// the old table did not have are numbered after INTERM. The symbols are
+// the tags that have their own inputs, the comma is the separator.
+func (grammar *NoticeGrammar) legacyDFA() (string, map[Tri]int) {
+	var symbols []string
+	for _, input := range grammar.inputs {
+		if input.hasTag && !input.hasWord && !input.hasContains && input.tag != "" && input.tag != "," {
+			symbols = append(symbols, input.tag)
+		}
+	}
+
+	oldStates := map[string]int{
+		"START": START, "LPAREN": LPAREN, "CCHAR": CCHAR, "RPAREN": RPAREN,
+		"NP": NP, "COMMA": COMMA, "CD": CD, "DASH": DASH, "IN": IN, "DT": DT,
+		"ACCEPT": ACCEPT, "REJECT": REJECT, "SYM": SYM, "OTHER": OTHER,
+		"CSYM": CSYM, "LPARENC": LPARENC,
+	}
+	number := make([]int, len(grammar.states))
+	next := INTERM + 1
+	for state, name := range grammar.states {
+		if old, ok := oldStates[name]; ok {
+			number[state] = old
+		} else {
+			number[state] = next
+			next++
+		}
+	}
+
+	dfa := make(map[Tri]int)
+	for state := range grammar.states {
+		for _, key := range legacyWords {
+			key.state = number[state]
+			dfa[key] = number[grammar.next(state, TaggedWord{Text: key.word, Tag: key.pos})]
+		}
+		for _, tag := range legacyTags {
+			key := Tri{state: number[state], word: "X", pos: tag}
+			dfa[key] = number[grammar.next(state, TaggedWord{Text: "X", Tag: tag})]
+		}
+	}
+	return strings.Join(symbols, ","), dfa
+}
@@ -0,0 +1,168 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Unit tests for loading and checking notice grammars
+
+package tagger
+
+import (
+	"strings"
+	"testing"
+)
+
+// a grammar small enough to read, it only knows "copyright YEAR."
+const tinyGrammar = `
+input copyright word=copyright
+input year      tag=cd
+input period    tag=.
+
+start  OUT
+begin  KEYWORD
+accept DONE
+reject OUT
+final  YEAR
+
+state *
+	copyright -> KEYWORD
+	default   -> OUT
+
+state OUT
+state KEYWORD
+	year -> YEAR
+state YEAR
+	year   -> YEAR
+	period -> DONE
+state DONE
+`
+
+func TestParseNoticeGrammar(t *testing.T) {
+	grammar, err := ParseNoticeGrammar(strings.NewReader(tinyGrammar))
+	if err != nil {
+		t.Fatalf("could not parse the tiny grammar: %v", err)
+	}
+	if strings.Join(grammar.States(), " ") != "OUT KEYWORD YEAR DONE" {
+		t.Errorf("unexpected states %v", grammar.States())
+	}
+
+	state := grammar.start
+	for _, word := range []TaggedWord{{Text: "Copyright", Tag: "nn"}, {Text: "2015", Tag: "cd"}, {Text: ".", Tag: "."}} {
+		state = grammar.next(state, word)
+	}
+	if !grammar.accept[state] {
+		t.Errorf("expected to end in DONE got %s", grammar.States()[state])
+	}
+
+	if len(DefaultNoticeGrammar().States()) != 22 {
+		t.Errorf("expected the default grammar to have 22 states got %d", len(DefaultNoticeGrammar().States()))
+	}
+}
+
+func TestLegacyDFA(t *testing.T) {
+	type LegacyTest struct {
+		Expected int
+		Key      Tri
+	}
+
+	// lookups the table New filled before the grammar gives the same answer
+	tests := []LegacyTest{
+		{Expected: START, Key: Tri{REJECT, "copyright", "nn"}},
+		{Expected: CCHAR, Key: Tri{LPAREN, "c", "nn"}},
+		{Expected: CSYM, Key: Tri{REJECT, "©", "sym"}},
+		{Expected: LPAREN, Key: Tri{CSYM, "X", "("}},
+		{Expected: CD, Key: Tri{START, "X", "cd"}},
+		{Expected: CD, Key: Tri{DASH, "X", "cd"}},
+		{Expected: ACCEPT, Key: Tri{ACCEPT, "X", "np"}},
+		{Expected: ACCEPT, Key: Tri{CD, "X", "."}},
+		{Expected: ACCEPT, Key: Tri{NP, "X", "X"}},
+		{Expected: REJECT, Key: Tri{DT, "X", "cd"}},
+	}
+
+	symbols, dfa := DefaultNoticeGrammar().legacyDFA()
+	if symbols != "(,),cd,np,dt,in,--,.,sym,cc" {
+		t.Errorf("expected the old symbols got %q", symbols)
+	}
+	for i, test := range tests {
+		if got, ok := dfa[test.Key]; !ok || got != test.Expected {
+			t.Errorf("Test %d: expected %v to go to %d got %d %v", i, test.Key, test.Expected, got, ok)
+		}
+	}
+	if len(copyrightTagger.CopyrightDFA) != len(dfa) || copyrightTagger.CopyrightSyms != symbols {
+		t.Errorf("expected New to fill the deprecated CopyrightDFA and CopyrightSyms")
+	}
+}
+
+func TestNoticeGrammarErrors(t *testing.T) {
+	type GrammarTest struct {
+		Expected string // part of the error message
+		Grammar  string
+	}
+
+	tests := []GrammarTest{
+		{
+			Expected: "state B has no transition on other, year",
+			Grammar:  "input year tag=cd\nstart A\nbegin A\nstate A\n  default -> B\nstate B\n",
+		},
+		{
+			Expected: "unreachable states C",
+			Grammar:  "start A\nbegin A\nstate *\n  default -> A\nstate A\nstate C\n",
+		},
+		{
+			Expected: "goes to unknown state Z",
+			Grammar:  "start A\nbegin A\nstate A\n  default -> Z\n",
+		},
+		{
+			Expected: "line 4: unknown input year",
+			Grammar:  "start A\nbegin A\nstate A\n  year -> A\n  default -> A\n",
+		},
+		{
+			Expected: "accept names unknown state Q",
+			Grammar:  "start A\nbegin A\naccept Q\nstate A\n  default -> A\n",
+		},
+		{
+			Expected: "needs exactly one start state",
+			Grammar:  "begin A\nstate A\n  default -> A\n",
+		},
+		{
+			Expected: "lead state B is not a begin state",
+			Grammar:  "start A\nbegin A\nlead B\nstate A\n  default -> B\nstate B\n  default -> A\n",
+		},
+		{
+			Expected: "line 1: unknown matcher \"pos\"",
+			Grammar:  "input year pos=cd\n",
+		},
+	}
+
+	for i, test := range tests {
+		_, err := ParseNoticeGrammar(strings.NewReader(test.Grammar))
+		if err == nil {
+			t.Errorf("Test %d: expected an error containing %q", i, test.Expected)
+		} else if !strings.Contains(err.Error(), test.Expected) {
+			t.Errorf("Test %d: expected an error containing %q got %q", i, test.Expected, err)
+		}
+	}
+}
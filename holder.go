@@ -0,0 +1,181 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// This file is about telling when two notices name the same holder.
+// "Free Software Foundation, Inc.", "Free Software Foundation Inc" and
+// "FSF" are all the same holder. Holders are normalized by dropping case,
+// punctuation and corporate endings, then an alias file the user gives
+// can map whatever is left onto one canonical name.
+//
+// The alias file is plain text, one canonical name per line followed
+// by an equals sign and a comma separated list of its aliases:
+//
+//	# comments start with a hash
+//	Free Software Foundation = FSF, The FSF
+//	International Business Machines = IBM, IBM Corp
+
+package tagger
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Maps the normalized form of an alias to its canonical holder name
+type HolderAliases map[string]string
+
+// endings that are dropped from the end of a holder when normalizing,
+// on top of the corporateSuffixes that are split off by commas
+var holderEndings = map[string]bool{
+	"corporation":  true,
+	"incorporated": true,
+	"limited":      true,
+	"company":      true,
+	"gmbh":         true,
+	"srl":          true,
+	"sarl":         true,
+	"kk":           true,
+	"oy":           true,
+	"ab":           true,
+	"nv":           true,
+}
+
+// words at the front of a holder that are not part of the name
+var holderPrefixes = map[string]bool{
+	"the": true,
+	"by":  true,
+}
+
+// Given a holder as it was written in a notice this returns the form used
+// to compare it to other holders. Case, punctuation, leading "the" or "by"
+// and corporate endings like Inc., Ltd or Corporation are all removed.
+func NormalizeHolder(holder string) string {
+	// anything that is not a letter or number separates words
+	words := strings.FieldsFunc(strings.ToLower(holder), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	for len(words) > 1 && holderPrefixes[words[0]] {
+		words = words[1:]
+	}
+	for len(words) > 1 && (corporateSuffixes[words[len(words)-1]] || holderEndings[words[len(words)-1]]) {
+		words = words[:len(words)-1]
+	}
+	return strings.Join(words, " ")
+}
+
+// Reads an alias file from the given path, see the top of this file
+// for what the file looks like
+func LoadHolderAliases(path string) (HolderAliases, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseHolderAliases(file)
+}
+
+// Reads alias lines from any reader, see the top of this file for
+// what the lines look like
+func ParseHolderAliases(reader io.Reader) (HolderAliases, error) {
+	var aliases = make(HolderAliases)
+
+	scanner := bufio.NewScanner(reader)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		canonical := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || NormalizeHolder(canonical) == "" {
+			return nil, fmt.Errorf("holder aliases line %d: expected canonical = alias, alias", lineNum)
+		}
+
+		key := NormalizeHolder(canonical)
+		if existing, ok := aliases[key]; ok && existing != canonical {
+			return nil, fmt.Errorf("holder aliases line %d: %q is already an alias of %q", lineNum, canonical, existing)
+		}
+		aliases[key] = canonical
+		for _, alias := range strings.Split(parts[1], ",") {
+			key := NormalizeHolder(alias)
+			if key == "" {
+				continue
+			}
+			if existing, ok := aliases[key]; ok && existing != canonical {
+				return nil, fmt.Errorf("holder aliases line %d: %q is already an alias of %q", lineNum, strings.TrimSpace(alias), existing)
+			}
+			aliases[key] = canonical
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return aliases, nil
+}
+
+// Returns the key holders that are the same compare equal by, always the
+// normalized form of the canonical name, and the name to show for the
+// holder, the canonical name as the alias file has it or the holder as
+// written when it has no alias. A nil HolderAliases only normalizes.
+func (aliases HolderAliases) Canonical(holder string) (key string, display string) {
+	key = NormalizeHolder(holder)
+	if canonical, ok := aliases[key]; ok {
+		return NormalizeHolder(canonical), canonical
+	}
+	return key, strings.Join(strings.Fields(holder), " ")
+}
+
+// Groups notices by the key Canonical returns for their holders. A notice
+// with more than one holder is listed under each of them, a notice with
+// no holder is listed under the empty string.
+func GroupByHolder(notices []Notice, aliases HolderAliases) map[string][]Notice {
+	var groups = make(map[string][]Notice)
+	for _, notice := range notices {
+		seen := make(map[string]bool)
+		for _, holder := range notice.Holders {
+			key, _ := aliases.Canonical(holder)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			groups[key] = append(groups[key], notice)
+		}
+		if len(seen) == 0 {
+			groups[""] = append(groups[""], notice)
+		}
+	}
+	return groups
+}
@@ -0,0 +1,114 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Unit tests for holder normalization and aliases
+
+package tagger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeHolder(t *testing.T) {
+	type HolderTest struct {
+		Expected string
+		Holder   string
+	}
+
+	tests := []HolderTest{
+		{Expected: "free software foundation", Holder: "Free Software Foundation, Inc."},
+		{Expected: "free software foundation", Holder: "Free Software Foundation Inc"},
+		{Expected: "free software foundation", Holder: "the  Free Software\n Foundation , Inc ."},
+		{Expected: "ibm", Holder: "IBM       Corporation"},
+		{Expected: "theodore ts o", Holder: "by Theodore Ts'o."},
+		{Expected: "acme", Holder: "ACME Co., Ltd."},
+		{Expected: "inc", Holder: "Inc."},
+		{Expected: "", Holder: " , "},
+	}
+
+	for i, test := range tests {
+		r := NormalizeHolder(test.Holder)
+		if r != test.Expected {
+			t.Errorf("Test %d: expected %q got %q", i, test.Expected, r)
+		}
+	}
+}
+
+func TestHolderAliases(t *testing.T) {
+	aliases, err := ParseHolderAliases(strings.NewReader(
+		"# holders we see a lot\n" +
+			"Free Software Foundation = FSF, The FSF\n" +
+			"\n" +
+			"International Business Machines = IBM, IBM Corp.\n"))
+	if err != nil {
+		t.Fatalf("could not parse aliases: %v", err)
+	}
+
+	type AliasTest struct {
+		Key     string
+		Display string
+		Holder  string
+	}
+	tests := []AliasTest{
+		{Key: "free software foundation", Display: "Free Software Foundation", Holder: "Free Software Foundation, Inc."},
+		{Key: "free software foundation", Display: "Free Software Foundation", Holder: "FSF"},
+		{Key: "international business machines", Display: "International Business Machines", Holder: "IBM Corporation"},
+		{Key: "python software foundation", Display: "Python Software Foundation", Holder: " Python  Software Foundation"},
+	}
+	for i, test := range tests {
+		key, display := aliases.Canonical(test.Holder)
+		if key != test.Key || display != test.Display {
+			t.Errorf("Test %d: expected %q %q got %q %q", i, test.Key, test.Display, key, display)
+		}
+	}
+
+	notices := []Notice{
+		{Text: "a", Holders: []string{"Free Software Foundation, Inc."}},
+		{Text: "b", Holders: []string{"FSF"}},
+		{Text: "c", Holders: []string{"IBM", "Free Software Foundation Inc"}},
+		{Text: "d"},
+	}
+	groups := GroupByHolder(notices, aliases)
+	if len(groups["free software foundation"]) != 3 {
+		t.Errorf("expected 3 FSF notices got %d", len(groups["free software foundation"]))
+	}
+	if len(groups["international business machines"]) != 1 || len(groups[""]) != 1 {
+		t.Errorf("unexpected groups %v", groups)
+	}
+
+	if _, err := ParseHolderAliases(strings.NewReader("FSF\n")); err == nil {
+		t.Errorf("expected an error for a line without =")
+	}
+	if _, err := ParseHolderAliases(strings.NewReader("A = X\nB = X\n")); err == nil {
+		t.Errorf("expected an error for an alias of two holders")
+	}
+	if _, err := ParseHolderAliases(strings.NewReader("A = X\nX = Y\n")); err == nil {
+		t.Errorf("expected an error for a canonical name that is an alias already")
+	}
+}
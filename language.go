@@ -0,0 +1,318 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// The tagger is trained on English and the notice DFA only knows the
+// English keywords, so notices in other languages are found here from
+// tables of keywords instead. Japanese and Chinese are not split on
+// spaces so the keywords are looked for in the raw text. Each keyword
+// marks a clause, the rest of its line up to a sentence end, and the
+// clause is a notice when it also has a year or a copyright marker.
+// A clause that overlaps notices the DFA found is merged with them, like
+// "© 2008 Société Générale. Tous droits réservés."
+
+package tagger
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// the language of a notice found by the DFA
+const englishLanguage string = "en"
+
+// A keyword that marks a notice in some language
+type noticeKeyword struct {
+	language string // ISO 639-1 code
+	pattern  *regexp.Regexp
+}
+
+// Longer keywords go before the shorter ones they contain
+var noticeKeywordTable = []noticeKeyword{
+	{"de", regexp.MustCompile(`(?i)urheberrechte?`)},
+	{"de", regexp.MustCompile(`(?i)alle\s+rechte\s+vorbehalten`)},
+	{"fr", regexp.MustCompile(`(?i)droits?\s+d['’]\s*auteur`)},
+	{"fr", regexp.MustCompile(`(?i)tous\s+droits\s+r[ée]serv[ée]s`)},
+	{"es", regexp.MustCompile(`(?i)derechos\s+de\s+autor`)},
+	{"es", regexp.MustCompile(`(?i)todos\s+los\s+derechos\s+reservados`)},
+	{"es", regexp.MustCompile(`(?i)derechos\s+reservados`)},
+	{"ja", regexp.MustCompile(`著作権`)},
+	{"ja", regexp.MustCompile(`無断(?:転載|複写|複製)`)},
+	{"zh", regexp.MustCompile(`版[权權]所有`)},
+	{"zh", regexp.MustCompile(`著作[权權]`)},
+	{"zh", regexp.MustCompile(`保留所有[权權]利`)},
+}
+
+// things that make a clause with a keyword a notice and not a mention
+var noticeMarkerPattern = regexp.MustCompile(`(?i)©|\(c\)|\bcopyright\b|\bcopr\.`)
+
+// the years in a notice, with the 年 of Japanese and Chinese dates
+var noticeYearPattern = regexp.MustCompile(`[0-9]{4}(?:\s*[-–,]\s*[0-9]{2,4})*\s*年?`)
+
+// any number, the four digit ones are the years of a notice
+var digitRunPattern = regexp.MustCompile(`[0-9]+`)
+
+// small words that can go between the years and the holder
+var holderPrepositions = regexp.MustCompile(`(?i)^(?:by|von|par|por|de)\s+`)
+
+// what a clause ends at besides the end of its line
+var clauseEnds = []string{"。", "．"}
+
+// leaders and punctuation trimmed off clauses and holders
+const clauseTrim string = " \t\r/*#;!-"
+const holderTrim string = " \t\r.,;:。、，．-"
+
+// Returns the ISO 639-1 code of the language of the first notice keyword
+// in the text, "en" when it only has the English ones and "" when it has
+// none at all.
+func DetectNoticeLanguage(text string) string {
+	if language, _, _ := firstNoticeKeyword(text); language != "" {
+		return language
+	}
+	if noticeMarkerPattern.MatchString(text) {
+		return englishLanguage
+	}
+	return ""
+}
+
+// Returns the language and byte span of the keyword that comes first in
+// the text, the language is "" when there is none
+func firstNoticeKeyword(text string) (string, int, int) {
+	language, start, end := "", -1, -1
+	for _, keyword := range noticeKeywordTable {
+		if span := keyword.pattern.FindStringIndex(text); span != nil && (start < 0 || span[0] < start) {
+			language, start, end = keyword.language, span[0], span[1]
+		}
+	}
+	return language, start, end
+}
+
+// A clause of the input with a keyword in it
+type foreignClause struct {
+	start    int
+	end      int
+	language string
+	keyword  string
+	stopped  bool // ended at a sentence end and not the end of the line
+}
+
+// Finds every clause of the input that has a notice keyword of some
+// language other than English
+func foreignClauses(inBytes []byte) []foreignClause {
+	var clauses = make([]foreignClause, 0)
+	if !utf8.Valid(inBytes) {
+		return clauses
+	}
+
+	for lineStart := 0; lineStart < len(inBytes); {
+		lineEnd := bytes.IndexByte(inBytes[lineStart:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(inBytes)
+		} else {
+			lineEnd += lineStart
+		}
+
+		for start := lineStart; start < lineEnd; {
+			end := lineEnd
+			for _, clauseEnd := range clauseEnds {
+				if at := bytes.Index(inBytes[start:lineEnd], []byte(clauseEnd)); at >= 0 && start+at+len(clauseEnd) < end {
+					end = start + at + len(clauseEnd)
+				}
+			}
+			text := string(inBytes[start:end])
+			if language, keywordStart, keywordEnd := firstNoticeKeyword(text); language != "" {
+				trimmed := strings.TrimLeft(text, clauseTrim)
+				clauseStart := start + len(text) - len(trimmed)
+				clauseEnd := start + len(strings.TrimRight(text, clauseTrim))
+				clauses = append(clauses, foreignClause{
+					start:    clauseStart,
+					end:      clauseEnd,
+					language: language,
+					keyword:  text[keywordStart:keywordEnd],
+					stopped:  end < lineEnd,
+				})
+			}
+			start = end
+		}
+		lineStart = lineEnd + 1
+	}
+	return clauses
+}
+
+// Adds the notices in other languages to the notices the DFA found in
+// the input, merging the ones that overlap. Every notice is given its
+// language. When mentions is true the clauses that are only mentions are
+// added instead and nothing is merged.
+func (copyrightTagger *Tagger) addForeignNotices(inBytes []byte, taggedSent []TaggedWord, notices []Notice, mentions bool) []Notice {
+	for i := range notices {
+		notices[i].Language = englishLanguage
+	}
+
+	for _, clause := range foreignClauses(inBytes) {
+		text := string(inBytes[clause.start:clause.end])
+		isNotice := noticeMarkerPattern.MatchString(text) || len(noticeYearPattern.FindString(text)) > 0
+
+		if mentions {
+			if !isNotice && !overlapsNotice(notices, clause.start, clause.end) {
+				notice := copyrightTagger.mkForeignNotice(inBytes, taggedSent, clause, clause.start, clause.end)
+				notice.Mention = 1
+				notice.Score = 0
+				notices = append(notices, notice)
+			}
+			continue
+		}
+
+		// fold any notices the clause overlaps into it
+		start, end := clause.start, clause.end
+		best := 0.0
+		kept := notices[:0]
+		for _, notice := range notices {
+			if notice.Start < clause.end && clause.start < notice.End {
+				if notice.Start < start {
+					start = notice.Start
+				}
+				if notice.End > end {
+					end = notice.End
+				}
+				if notice.Score > best {
+					best = notice.Score
+				}
+				isNotice = true
+				continue
+			}
+			kept = append(kept, notice)
+		}
+		notices = kept
+		if !isNotice {
+			continue
+		}
+		// the tagger does not split at 。 so the DFA can run past it
+		if clause.stopped && end > clause.end {
+			end = clause.end
+		}
+
+		notice := copyrightTagger.mkForeignNotice(inBytes, taggedSent, clause, start, end)
+		if best > notice.Score {
+			notice.Score = best
+		}
+		if notice.Score >= copyrightTagger.MinScore {
+			notices = append(notices, notice)
+		}
+	}
+
+	sort.SliceStable(notices, func(i, j int) bool {
+		return notices[i].Start < notices[j].Start
+	})
+	return notices
+}
+
+// returns true if any of the notices overlaps the span
+func overlapsNotice(notices []Notice, start int, end int) bool {
+	for _, notice := range notices {
+		if notice.Start < end && start < notice.End {
+			return true
+		}
+	}
+	return false
+}
+
+// Builds the Notice for the span of the input around a foreign clause
+func (copyrightTagger *Tagger) mkForeignNotice(inBytes []byte, taggedSent []TaggedWord, clause foreignClause, start int, end int) Notice {
+	notice := Notice{
+		Start:    start,
+		End:      end,
+		Text:     string(inBytes[start:end]),
+		Language: clause.language,
+		Words:    make([]TaggedWord, 0),
+	}
+	for _, word := range taggedSent {
+		if word.Start >= start && word.End <= end && word.End > word.Start {
+			notice.Words = append(notice.Words, word)
+		}
+	}
+
+	notice.Marker = noticeMarker(notice.Words)
+	if notice.Marker == "" {
+		notice.Marker = clause.keyword
+	}
+	notice.Years = textYears(notice.Text)
+	notice.YearRanges = ParseYears(notice.Text, currentYear())
+	notice.Holders = foreignHolders(notice.Text)
+
+	// the DFA's score with a keyword as strong as copyright and tags the
+	// tagger can not be sure of
+	notice.Score = scoreMarker * markerStrength["Copyright"]
+	if strings.Contains(notice.Text, "©") {
+		notice.Score = scoreMarker * markerStrength["©"]
+	}
+	if len(notice.YearRanges.Ranges) > 0 {
+		notice.Score += scoreYears
+		if len(notice.YearRanges.Problems) > 0 {
+			notice.Score -= scoreYears / 2
+		}
+	}
+	if len(notice.Holders) > 0 {
+		notice.Score += scoreHolder
+	}
+	notice.Score += scoreTags * unknownTagConfidence
+	return notice
+}
+
+// Returns every four digit number in the text, the years of a notice
+// that was not tagged
+func textYears(text string) []int {
+	var years = make([]int, 0)
+	for _, number := range digitRunPattern.FindAllString(text, -1) {
+		if year, err := strconv.Atoi(number); err == nil && len(number) == 4 {
+			years = append(years, year)
+		}
+	}
+	return years
+}
+
+// Returns the holder of a foreign notice, what is left of the text once
+// the keywords, markers and years are taken out
+func foreignHolders(text string) []string {
+	for _, keyword := range noticeKeywordTable {
+		text = keyword.pattern.ReplaceAllString(text, " ")
+	}
+	text = noticeMarkerPattern.ReplaceAllString(text, " ")
+	text = noticeYearPattern.ReplaceAllString(text, " ")
+	text = strings.Join(strings.Fields(text), " ")
+	text = strings.Trim(text, holderTrim)
+	text = holderPrepositions.ReplaceAllString(text, "")
+	text = strings.Trim(text, holderTrim)
+
+	if text == "" {
+		return make([]string, 0)
+	}
+	return []string{text}
+}
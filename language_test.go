@@ -0,0 +1,131 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Unit tests for notices in languages other than English
+
+package tagger
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestForeignNotices(t *testing.T) {
+	type LanguageTest struct {
+		Expected string // the notice text
+		Language string
+		Years    []int
+		Holder   string
+		Text     string
+	}
+
+	tests := []LanguageTest{
+		{
+			Expected: "Urheberrecht (c) 2004 Max Mustermann GmbH. Alle Rechte vorbehalten.",
+			Language: "de",
+			Years:    []int{2004},
+			Holder:   "Max Mustermann GmbH",
+			Text:     "/*\n * Urheberrecht (c) 2004 Max Mustermann GmbH. Alle Rechte vorbehalten.\n */",
+		},
+		{
+			Expected: "Copyright © 2008 Société Générale. Tous droits réservés.",
+			Language: "fr",
+			Years:    []int{2008},
+			Holder:   "Société Générale",
+			Text:     "# Copyright © 2008 Société Générale. Tous droits réservés.\n",
+		},
+		{
+			Expected: "Derechos reservados © 2010 Editorial Trillas",
+			Language: "es",
+			Years:    []int{2010},
+			Holder:   "Editorial Trillas",
+			Text:     "Derechos reservados © 2010 Editorial Trillas\n",
+		},
+		{
+			Expected: "著作権 2005-2009 株式会社サンプル",
+			Language: "ja",
+			Years:    []int{2005, 2009},
+			Holder:   "株式会社サンプル",
+			Text:     "// 著作権 2005-2009 株式会社サンプル\n",
+		},
+		{
+			Expected: "版权所有 (C) 2010 华为技术有限公司。",
+			Language: "zh",
+			Years:    []int{2010},
+			Holder:   "华为技术有限公司",
+			Text:     "版权所有 (C) 2010 华为技术有限公司。保留一切权利",
+		},
+	}
+
+	for i, test := range tests {
+		notices := copyrightTagger.ExtractNotices([]byte(test.Text))
+		if len(notices) != 1 {
+			t.Errorf("Test %d: expected 1 notice got %d", i, len(notices))
+			continue
+		}
+		notice := notices[0]
+		if notice.Text != test.Expected || notice.Text != test.Text[notice.Start:notice.End] {
+			t.Errorf("Test %d: expected %q got %q", i, test.Expected, notice.Text)
+		}
+		if notice.Language != test.Language {
+			t.Errorf("Test %d: expected language %q got %q", i, test.Language, notice.Language)
+		}
+		if !reflect.DeepEqual(notice.Years, test.Years) {
+			t.Errorf("Test %d: expected years %v got %v", i, test.Years, notice.Years)
+		}
+		if len(notice.Holders) != 1 || notice.Holders[0] != test.Holder {
+			t.Errorf("Test %d: expected holder %q got %q", i, test.Holder, notice.Holders)
+		}
+		if !copyrightTagger.Match([]byte(test.Text)) {
+			t.Errorf("Test %d: expected a match", i)
+		}
+	}
+
+	// a keyword on its own is only a mention
+	text := []byte("Tous droits réservés.")
+	if copyrightTagger.Match(text) || len(copyrightTagger.ExtractMentions(text)) != 1 {
+		t.Errorf("expected %q to be a mention", text)
+	}
+}
+
+func TestDetectNoticeLanguage(t *testing.T) {
+	tests := map[string]string{
+		"Copyright 2015 Eric Knapik":    "en",
+		"Alle Rechte vorbehalten":       "de",
+		"droits d’auteur":               "fr",
+		"Todos los derechos reservados": "es",
+		"無断転載を禁じます":                     "ja",
+		"著作權":                           "zh",
+		"nothing to see here":           "",
+	}
+	for text, expected := range tests {
+		if got := DetectNoticeLanguage(text); got != expected {
+			t.Errorf("expected %q for %q got %q", expected, text, got)
+		}
+	}
+}
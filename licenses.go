@@ -0,0 +1,346 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// This file is about telling which license a piece of text is, item 4 of
+// the TODO. The text and the bundled SPDX license templates are both cut
+// into normalized tokens, lower case runs of letters and digits, so
+// punctuation, comment leaders and line breaks do not matter. Each
+// template is then lined up with the text by a local alignment: tokens
+// that match score a point, tokens of the text the template does not have
+// and tokens of the template the text left out lose one. Optional template
+// sections can be left out for free and a var section takes up any number
+// of tokens of the text for free. The best alignment gives the span of
+// the license and how much of the template it covers.
+
+package tagger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ExtractLicenses leaves out matches covering less than this much of
+// their template
+const MinLicenseCoverage float64 = 0.8
+
+// A license found in the input
+type LicenseMatch struct {
+	ID       string  `json:"id"`       // the SPDX identifier of the license
+	Coverage float64 `json:"coverage"` // how much of the template was found, 0 to 1
+	Start    int     `json:"start"`    // byte offset of the first matched word
+	End      int     `json:"end"`      // byte offset just past the last matched word
+	// from a SPDX-License-Identifier tag, the ID is the whole expression
+	Declared bool `json:"declared"`
+	score    int
+}
+
+// A license template ready to be matched
+type LicenseTemplate struct {
+	ID       string
+	tokens   []templateToken
+	required int // tokens that are not optional or a var
+}
+
+// One token of a template, a var has no word
+type templateToken struct {
+	word     string
+	optional bool
+}
+
+// A normalized word of the input and where it is
+type licenseToken struct {
+	word  string
+	start int
+	end   int
+}
+
+// spellings that mean the same thing to a license
+var licenseSpellings = map[string]string{
+	"licence":  "license",
+	"licences": "licenses",
+	"licenced": "licensed",
+	"favour":   "favor",
+	"whilst":   "while",
+}
+
+// Returns every template bundled with the package sorted by identifier
+func DefaultLicenseTemplates() []*LicenseTemplate {
+	var templates = make([]*LicenseTemplate, 0, len(bundledLicenseTemplates))
+	for id, text := range bundledLicenseTemplates {
+		template, err := ParseLicenseTemplate(id, text)
+		if err != nil {
+			panic("the bundled license template " + id + " is broken: " + err.Error())
+		}
+		templates = append(templates, template)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].ID < templates[j].ID
+	})
+	return templates
+}
+
+// Reads a license template in the SPDX template format
+func ParseLicenseTemplate(id string, text string) (*LicenseTemplate, error) {
+	template := &LicenseTemplate{ID: id}
+	depth := 0
+	for len(text) > 0 {
+		open := strings.Index(text, "<<")
+		if open < 0 {
+			open = len(text)
+		}
+		template.addText(text[:open], depth > 0)
+		if open == len(text) {
+			break
+		}
+
+		close := strings.Index(text[open:], ">>")
+		if close < 0 {
+			return nil, fmt.Errorf("license template %s: unclosed <<", id)
+		}
+		rule := text[open+2 : open+close]
+		text = text[open+close+2:]
+		switch {
+		case rule == "beginOptional" || strings.HasPrefix(rule, "beginOptional;"):
+			depth++
+		case rule == "endOptional":
+			if depth == 0 {
+				return nil, fmt.Errorf("license template %s: endOptional with no beginOptional", id)
+			}
+			depth--
+		case strings.HasPrefix(rule, "var;"):
+			template.tokens = append(template.tokens, templateToken{optional: true})
+		default:
+			return nil, fmt.Errorf("license template %s: unknown rule <<%s>>", id, rule)
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("license template %s: beginOptional with no endOptional", id)
+	}
+	if template.required == 0 {
+		return nil, fmt.Errorf("license template %s: has no text", id)
+	}
+	return template, nil
+}
+
+// adds the words of some template text
+func (template *LicenseTemplate) addText(text string, optional bool) {
+	for _, token := range licenseTokens([]byte(text)) {
+		template.tokens = append(template.tokens, templateToken{word: token.word, optional: optional})
+		if !optional {
+			template.required++
+		}
+	}
+}
+
+// Cuts the input into normalized words with their byte offsets
+func licenseTokens(inBytes []byte) []licenseToken {
+	var tokens = make([]licenseToken, 0)
+	start := -1
+	for i := 0; i <= len(inBytes); {
+		r, size := rune(0), 1
+		if i < len(inBytes) {
+			r, size = utf8.DecodeRune(inBytes[i:])
+		}
+		isWord := i < len(inBytes) && (unicode.IsLetter(r) || unicode.IsDigit(r))
+		switch {
+		case isWord && start < 0:
+			start = i
+		case !isWord && start >= 0:
+			word := strings.ToLower(string(inBytes[start:i]))
+			if spelling, ok := licenseSpellings[word]; ok {
+				word = spelling
+			}
+			tokens = append(tokens, licenseToken{word: word, start: start, end: i})
+			start = -1
+		}
+		i += size
+	}
+	return tokens
+}
+
+// Finds the licenses of the templates in the input. Each template is
+// matched once, where it matches best, and when two matches overlap the
+// one that lines up better is kept. Matches covering less than
+// minCoverage of their template are left out.
+func MatchLicenses(templates []*LicenseTemplate, inBytes []byte, minCoverage float64) []LicenseMatch {
+	tokens := licenseTokens(inBytes)
+	words := make(map[string]bool, len(tokens))
+	for _, token := range tokens {
+		words[token.word] = true
+	}
+
+	var found = make([]LicenseMatch, 0)
+	for _, template := range templates {
+		if template.possibleCoverage(words) < minCoverage {
+			continue
+		}
+		if match, ok := template.align(tokens); ok && match.Coverage >= minCoverage {
+			found = append(found, match)
+		}
+	}
+
+	// the best aligned matches win the overlaps
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].score > found[j].score
+	})
+	var matches = make([]LicenseMatch, 0, len(found))
+	for _, match := range found {
+		overlaps := false
+		for _, kept := range matches {
+			overlaps = overlaps || (match.Start < kept.End && kept.Start < match.End)
+		}
+		if !overlaps {
+			matches = append(matches, match)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Start < matches[j].Start
+	})
+	return matches
+}
+
+// Given any input this returns the licenses of the Tagger's templates in
+// it and the licenses declared by SPDX-License-Identifier tags, see
+// spdx.go. The offsets are offsets into inBytes like those of
+// ExtractNotices.
+func (copyrightTagger *Tagger) ExtractLicenses(inBytes []byte) []LicenseMatch {
+	matches := MatchLicenses(copyrightTagger.Licenses, inBytes, MinLicenseCoverage)
+	matches = append(matches, declaredLicenses(inBytes)...)
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Start < matches[j].Start
+	})
+	return matches
+}
+
+// The most of the template the input could cover, the share of required
+// tokens whose word is in the input at all. It is cheap and saves lining
+// up templates that can not match.
+func (template *LicenseTemplate) possibleCoverage(words map[string]bool) float64 {
+	present := 0
+	for _, token := range template.tokens {
+		if token.word != "" && !token.optional && words[token.word] {
+			present++
+		}
+	}
+	return float64(present) / float64(template.required)
+}
+
+// one cell of the alignment, the best way to line up the template up to
+// some token with the input up to some token
+type alignCell struct {
+	score   int
+	matched int // required template tokens matched
+	first   int // index of the first input token matched, -1 for none
+	last    int // index of the last input token matched
+}
+
+// Lines the template up with the input tokens, see the top of the file.
+// Only two rows of the table are kept so memory is the template's length.
+func (template *LicenseTemplate) align(tokens []licenseToken) (LicenseMatch, bool) {
+	width := len(template.tokens) + 1
+	prev := make([]alignCell, width)
+	curr := make([]alignCell, width)
+	empty := alignCell{first: -1, last: -1}
+	for j := range prev {
+		prev[j] = empty
+	}
+
+	best := empty
+	better := func(a alignCell, b alignCell) bool {
+		return a.score > b.score || (a.score == b.score && a.matched > b.matched)
+	}
+
+	for i, token := range tokens {
+		curr[0] = empty
+		for j := 1; j < width; j++ {
+			element := template.tokens[j-1]
+			cell := empty
+
+			// the input token lines up with the template token
+			if element.word == "" {
+				if candidate := prev[j-1]; better(candidate, cell) {
+					cell = candidate
+				}
+			} else if element.word == token.word {
+				candidate := prev[j-1]
+				candidate.score++
+				if !element.optional {
+					candidate.matched++
+				}
+				if candidate.first < 0 {
+					candidate.first = i
+				}
+				candidate.last = i
+				if better(candidate, cell) {
+					cell = candidate
+				}
+			}
+
+			// the input token is extra, free inside a var
+			candidate := prev[j]
+			if element.word != "" {
+				candidate.score--
+			}
+			if better(candidate, cell) {
+				cell = candidate
+			}
+
+			// the template token is left out, free when it is optional
+			candidate = curr[j-1]
+			if !element.optional {
+				candidate.score--
+			}
+			if better(candidate, cell) {
+				cell = candidate
+			}
+
+			if cell.score <= 0 {
+				cell = empty
+			}
+			curr[j] = cell
+			if better(cell, best) {
+				best = cell
+			}
+		}
+		prev, curr = curr, prev
+	}
+
+	if best.first < 0 {
+		return LicenseMatch{}, false
+	}
+	return LicenseMatch{
+		ID:       template.ID,
+		Coverage: float64(best.matched) / float64(template.required),
+		Start:    tokens[best.first].start,
+		End:      tokens[best.last].end,
+		score:    best.score,
+	}, true
+}
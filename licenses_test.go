@@ -0,0 +1,157 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Unit tests for license matching
+
+package tagger
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestExtractLicenses(t *testing.T) {
+	type LicenseTest struct {
+		Expected string // the SPDX identifier, "" for none
+		Text     string
+	}
+
+	tests := []LicenseTest{
+		{
+			Expected: "MIT",
+			Text:     "Copyright (c) 2015 Someone\n\n" + mitTemplate[strings.Index(mitTemplate, "Permission"):],
+		},
+		{
+			Expected: "Apache-2.0",
+			Text: "// Copyright 2020 Google LLC\n//\n// Licensed under the Apache License, Version 2.0 (the \"License\");\n" +
+				"// you may not use this file except in compliance with the License.\n" +
+				"// You may obtain a copy of the License at\n//\n//      https://www.apache.org/licenses/LICENSE-2.0\n//\n" +
+				"// Unless required by applicable law or agreed to in writing, software\n" +
+				"// distributed under the License is distributed on an \"AS IS\" BASIS,\n" +
+				"// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.\n" +
+				"// See the License for the specific language governing permissions and\n" +
+				"// limitations under the License.\n\npackage main\n",
+		},
+		{
+			Expected: "BSD-3-Clause",
+			Text: "Copyright (c) 2009 The Go Authors. All rights reserved.\n\n" +
+				strings.Replace(bsd3ClauseTemplate[strings.Index(bsd3ClauseTemplate, "Redistribution"):],
+					`<<var;name="organizationClause3";original="the copyright holder";match=".+">>`, "Google Inc.", 1),
+		},
+		{
+			Expected: "GPL-3.0-or-later",
+			Text: "# This program is free software: you can redistribute it and/or modify\n" +
+				"# it under the terms of the GNU General Public License as published by\n" +
+				"# the Free Software Foundation, either version 3 of the License, or\n" +
+				"# (at your option) any later version.\n#\n" +
+				"# This program is distributed in the hope that it will be useful,\n" +
+				"# but WITHOUT ANY WARRANTY; without even the implied warranty of\n" +
+				"# MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the\n" +
+				"# GNU General Public License for more details.\n#\n" +
+				"# You should have received a copy of the GNU General Public License\n" +
+				"# along with this program.  If not, see <http://www.gnu.org/licenses/>.\n",
+		},
+		{
+			Expected: "",
+			Text:     "Permission is hereby granted, free of charge, to any person obtaining a copy",
+		},
+	}
+
+	for i, test := range tests {
+		matches := copyrightTagger.ExtractLicenses([]byte(test.Text))
+		if test.Expected == "" {
+			if len(matches) != 0 {
+				t.Errorf("Test %d: expected no license got %s", i, matches[0].ID)
+			}
+			continue
+		}
+		if len(matches) != 1 {
+			t.Errorf("Test %d: expected 1 license got %v", i, matches)
+			continue
+		}
+		if matches[0].ID != test.Expected {
+			t.Errorf("Test %d: expected %s got %s", i, test.Expected, matches[0].ID)
+		}
+		if matches[0].Coverage < 0.95 || matches[0].Coverage > 1 {
+			t.Errorf("Test %d: expected full coverage got %f", i, matches[0].Coverage)
+		}
+	}
+}
+
+// The header of every file of this package is the BSD 2 clause license
+// right after the notice
+func TestHeaderLicense(t *testing.T) {
+	raw, err := ioutil.ReadFile("licenses.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := raw[:bytes.Index(raw, []byte("*/"))]
+
+	matches := copyrightTagger.ExtractLicenses(header)
+	if len(matches) != 1 || matches[0].ID != "BSD-2-Clause" {
+		t.Fatalf("expected BSD-2-Clause got %v", matches)
+	}
+	if !bytes.HasPrefix(header[matches[0].Start:], []byte("Redistribution and use")) ||
+		!bytes.HasSuffix(header[:matches[0].End], []byte("SUCH DAMAGE")) {
+		t.Errorf("unexpected span %q", header[matches[0].Start:matches[0].End])
+	}
+
+	notices := copyrightTagger.ExtractNotices(header)
+	if len(notices) != 1 || notices[0].End > matches[0].Start {
+		t.Errorf("expected the notice to come before the license")
+	}
+}
+
+func TestPartialLicense(t *testing.T) {
+	text := mitTemplate[:len(mitTemplate)/2]
+	templates := DefaultLicenseTemplates()
+	if matches := MatchLicenses(templates, []byte(text), MinLicenseCoverage); len(matches) != 0 {
+		t.Errorf("expected half a license to be left out got %v", matches)
+	}
+	matches := MatchLicenses(templates, []byte(text), 0.3)
+	if len(matches) != 1 || matches[0].ID != "MIT" || matches[0].Coverage > 0.7 {
+		t.Errorf("expected half of MIT got %v", matches)
+	}
+}
+
+func TestParseLicenseTemplate(t *testing.T) {
+	template, err := ParseLicenseTemplate("X", "a <<beginOptional>>b <<var;name=\"c\";original=\"c\";match=\".+\">><<endOptional>> d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(template.tokens) != 4 || template.required != 2 {
+		t.Errorf("expected 4 tokens, 2 required got %d, %d", len(template.tokens), template.required)
+	}
+	for _, broken := range []string{"a <<beginOptional>> b", "a <<endOptional>>", "a <<what>>", "a <<var", "<<var;name=\"x\">>"} {
+		if _, err := ParseLicenseTemplate("X", broken); err == nil {
+			t.Errorf("expected an error for %q", broken)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// License texts talk about copyright notices all the time, "retain the
+// above copyright notice" or "THE COPYRIGHT HOLDERS AND CONTRIBUTORS".
+// The notice DFA happily takes those for notices, they are mentions of
+// one. Every run the DFA keeps gets a mention score from the words around
+// the copyright keyword and whatever years and holders it has. Runs
+// scoring at or above the Tagger's MentionThreshold are mentions, they are
+// left out of Match, Extract, FindAllIndex and ExtractNotices and are
+// returned by ExtractMentions instead.
+
+package tagger
+
+import (
+	"strings"
+)
+
+// The MentionThreshold New sets. A threshold above 1 treats every
+// mention as a notice, a threshold of 0 makes everything a mention.
+const DefaultMentionThreshold float64 = 0.5
+
+// how much each clue adds to the mention score
+const (
+	mentionDeterminer float64 = 0.4  // "the copyright", "above copyright"
+	mentionReference  float64 = 0.5  // "copyright notice", "copyright holders"
+	mentionNoYears    float64 = 0.2  // a real notice nearly always has a year
+	mentionNoHolder   float64 = 0.2  // and says who holds it
+	mentionSymbol     float64 = -0.4 // nobody writes © when talking about notices
+)
+
+// words before copyright that point at some other notice
+var mentionDeterminers = map[string]bool{
+	"the":        true,
+	"this":       true,
+	"that":       true,
+	"these":      true,
+	"those":      true,
+	"above":      true,
+	"such":       true,
+	"any":        true,
+	"all":        true,
+	"each":       true,
+	"no":         true,
+	"our":        true,
+	"its":        true,
+	"their":      true,
+	"your":       true,
+	"said":       true,
+	"same":       true,
+	"following":  true,
+	"original":   true,
+	"applicable": true,
+}
+
+// words after copyright that show it is talked about not claimed
+var mentionReferences = map[string]bool{
+	"notice":       true,
+	"notices":      true,
+	"holder":       true,
+	"holders":      true,
+	"owner":        true,
+	"owners":       true,
+	"law":          true,
+	"laws":         true,
+	"statement":    true,
+	"statements":   true,
+	"license":      true,
+	"licenses":     true,
+	"protection":   true,
+	"infringement": true,
+	"interest":     true,
+	"interests":    true,
+	"office":       true,
+	"act":          true,
+}
+
+// Given any input this returns every mention of a copyright notice in it,
+// the runs ExtractNotices leaves out, in the order they appear.
+func (copyrightTagger *Tagger) ExtractMentions(inBytes []byte) []Notice {
+	var mentions = make([]Notice, 0)
+
+	taggedSent := copyrightTagger.TagBytes(inBytes)
+	for _, run := range copyrightTagger.scanNotices(taggedSent, 0, true) {
+		mentions = append(mentions, mkNotice(inBytes, run))
+	}
+	mentions = copyrightTagger.addForeignNotices(inBytes, taggedSent, mentions, true)
+	setPositions(inBytes, mentions)
+	return mentions
+}
+
+// Scores how much the run of words starting at taggedSent[runStart] looks
+// like a mention of a notice, 0 is a notice and 1 is surely a mention.
+// The words right before and after the copyright keyword are looked at in
+// the whole sentence since the run often stops at them.
+func mentionScore(taggedSent []TaggedWord, runStart int, words []TaggedWord) float64 {
+	score := 0.0
+
+	for i, word := range words {
+		lower := strings.ToLower(word.Text)
+		if !fuzzyCopyright.Match(lower) && strings.TrimSuffix(lower, ".") != "copr" {
+			continue
+		}
+		at := runStart + i
+		if at > 0 && mentionDeterminers[strings.ToLower(taggedSent[at-1].Text)] {
+			score += mentionDeterminer
+		}
+		if at+1 < len(taggedSent) && mentionReferences[strings.ToLower(taggedSent[at+1].Text)] {
+			score += mentionReference
+		}
+		break
+	}
+
+	if len(noticeYears(words)) == 0 {
+		score += mentionNoYears
+	}
+	if !hasHolder(words) {
+		score += mentionNoHolder
+	}
+	for i, word := range words {
+		if strings.Contains(word.Text, "©") || (word.Text == "(" && i+2 < len(words) &&
+			strings.ToLower(words[i+1].Text) == "c" && words[i+2].Text == ")") {
+			score += mentionSymbol
+			break
+		}
+	}
+
+	if score < 0 {
+		return 0
+	}
+	if score > 1 {
+		return 1
+	}
+	return score
+}
+
+// returns true if some proper noun in the words could be a holder, the
+// words of a mention like HOLDERS do not count
+func hasHolder(words []TaggedWord) bool {
+	for _, word := range words[:rightsReserved(words)] {
+		lower := strings.TrimSuffix(strings.ToLower(word.Text), ".")
+		if word.Tag == "np" && !noticeKeywords[lower] && !mentionReferences[lower] && !isFuzzyCopyright(lower) {
+			return true
+		}
+	}
+	return false
+}
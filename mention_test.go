@@ -0,0 +1,124 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Unit tests for telling notices from mentions of notices
+
+package tagger
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func TestMentions(t *testing.T) {
+	type MentionTest struct {
+		Expected bool // true when the text is only a mention
+		Text     string
+	}
+
+	tests := []MentionTest{
+		{
+			Expected: true,
+			Text:     "1. Redistributions of source code must retain the above copyright notice, this list of conditions.",
+		},
+		{
+			Expected: true,
+			Text:     "THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS \"AS IS\"",
+		},
+		{
+			Expected: true,
+			Text:     "IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE",
+		},
+		{
+			Expected: false,
+			Text:     "Copyright (c) 2015 Eric Knapik, All Rights Reserved",
+		},
+		{
+			Expected: false,
+			Text:     "Copyright 2004 by Theodore Ts'o.",
+		},
+		{
+			Expected: false,
+			Text:     " © 2001-2014 Python Software Foundation",
+		},
+	}
+
+	for i, test := range tests {
+		notices := copyrightTagger.ExtractNotices([]byte(test.Text))
+		mentions := copyrightTagger.ExtractMentions([]byte(test.Text))
+		// the DFA drops some mentions on its own so there may be none
+		if test.Expected && len(notices) != 0 {
+			t.Errorf("Test %d: expected no notices got %q", i, notices[0].Text)
+		}
+		if !test.Expected && (len(notices) == 0 || len(mentions) != 0) {
+			t.Errorf("Test %d: expected only notices got notices %d mentions %d", i, len(notices), len(mentions))
+		}
+		if test.Expected && copyrightTagger.Match([]byte(test.Text)) {
+			t.Errorf("Test %d: a mention should not match", i)
+		}
+	}
+}
+
+func TestMentionThreshold(t *testing.T) {
+	text := []byte("THE COPYRIGHT HOLDERS AND CONTRIBUTORS")
+	if len(copyrightTagger.ExtractMentions(text)) != 1 {
+		t.Errorf("expected one mention in %q", text)
+	}
+
+	// above 1 nothing is a mention
+	lenient := *copyrightTagger
+	lenient.MentionThreshold = 1.1
+	if len(lenient.ExtractMentions(text)) != 0 || len(lenient.ExtractNotices(text)) == 0 {
+		t.Errorf("expected mentions to be notices with a threshold above 1")
+	}
+
+	// at 0 everything is
+	strict := *copyrightTagger
+	strict.MentionThreshold = 0
+	if len(strict.ExtractNotices([]byte("Copyright 2015 Eric Knapik"))) != 0 {
+		t.Errorf("expected no notices with a threshold of 0")
+	}
+}
+
+// The header of this package has one notice and a few mentions
+func TestHeaderMentions(t *testing.T) {
+	raw, err := ioutil.ReadFile("mention.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := raw[:bytes.Index(raw, []byte("*/"))]
+
+	notices := copyrightTagger.ExtractNotices(header)
+	if len(notices) != 1 || len(notices[0].Holders) == 0 || notices[0].Holders[0] != "Eric Knapik" {
+		t.Errorf("expected the one notice of Eric Knapik got %v", notices)
+	}
+	if len(copyrightTagger.ExtractMentions(header)) == 0 {
+		t.Errorf("expected the license to have mentions")
+	}
+}
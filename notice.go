@@ -0,0 +1,234 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// This file turns the tagged words the notice DFA picked out
+// into Notice objects. A Notice remembers exactly where it came from
+// in the input so callers do not have to guess at the spacing from
+// the tagged words, and it pulls out the pieces people usually want
+// from a notice: the marker, the years and who holds the copyright.
+
+package tagger
+
+import (
+	"strconv"
+	"strings"
+)
+
+// A single copyright notice found in the input
+type Notice struct {
+	Start   int          `json:"start"`   // byte offset of the first byte of the notice
+	End     int          `json:"end"`     // byte offset just past the last byte of the notice
+	Text    string       `json:"text"`    // the notice exactly as it is in the input
+	Marker  string       `json:"marker"`  // "Copyright", "(c)" or "©", whichever came first
+	Years   []int        `json:"years"`   // every year mentioned in the notice in order
+	Holders []string     `json:"holders"` // who the notice says holds the copyright
+	Words   []TaggedWord `json:"-"`       // the tagged words that make up the notice
+
+	// the years of the notice as sorted ranges, see ParseYears
+	YearRanges NoticeYears `json:"year_ranges"`
+	// how much the notice reads like a mention of a notice, 0 to 1,
+	// see mention.go
+	Mention float64 `json:"mention"`
+	// how sure the tagger is this is a notice, 0 to 1, see score.go
+	Score float64 `json:"score"`
+	// the ISO 639-1 code of the language of the notice, see language.go
+	Language string `json:"language"`
+	// the Marker is a misspelled Copyright, "Copyrigth" or "C0pyright",
+	// and scores lower, see fuzzy.go
+	Fuzzy bool `json:"fuzzy"`
+	// the line and column of Start and of End, see position.go
+	StartPos Position `json:"start_pos"`
+	EndPos   Position `json:"end_pos"`
+}
+
+// corporate endings that belong to the holder before them even when
+// they are split off by a comma, "Free Software Foundation, Inc."
+var corporateSuffixes = map[string]bool{
+	"inc":  true,
+	"ltd":  true,
+	"llc":  true,
+	"llp":  true,
+	"corp": true,
+	"co":   true,
+	"gmbh": true,
+	"ag":   true,
+	"sa":   true,
+	"bv":   true,
+	"plc":  true,
+	"pty":  true,
+}
+
+// Given any input this returns every copyright notice in it
+// in the order they appear. An empty slice means there was no notice.
+func (copyrightTagger *Tagger) ExtractNotices(inBytes []byte) []Notice {
+	var notices = make([]Notice, 0)
+
+	// Before I can match for copyright notice I need the sentence tagged
+	taggedSent := copyrightTagger.TagBytes(inBytes)
+	for _, run := range copyrightTagger.scanNotices(taggedSent, 0, false) {
+		notices = append(notices, mkNotice(inBytes, run))
+	}
+	notices = copyrightTagger.addForeignNotices(inBytes, taggedSent, notices, false)
+	notices = addSPDXNotices(inBytes, taggedSent, notices)
+	setPositions(inBytes, notices)
+	return notices
+}
+
+// Builds the Notice for one run of the scanner out of the input the
+// words were tagged from. The notice ends with the last word of the run
+// unless that word only closed it.
+func mkNotice(inBytes []byte, run noticeRun) Notice {
+	words := run.words
+	last := len(words) - 1
+	if run.closed && last > 0 {
+		last--
+	}
+	notice := Notice{
+		Start:   words[0].Start,
+		End:     words[last].End,
+		Words:   words,
+		Mention: run.mention,
+		Score:   run.score,
+	}
+	if notice.End < notice.Start {
+		notice.End = notice.Start
+	}
+	notice.Text = string(inBytes[notice.Start:notice.End])
+	notice.Marker = noticeMarker(words)
+	notice.Fuzzy = isFuzzyCopyright(notice.Marker)
+	notice.Years = noticeYears(words)
+	notice.YearRanges = ParseYears(notice.Text, currentYear())
+	notice.Holders = noticeHolders(inBytes, words)
+	return notice
+}
+
+// words that start a notice and so are never part of a holder, the
+// tagger often takes them for proper nouns
+var noticeKeywords = map[string]bool{
+	"copyright": true,
+	"copr":      true,
+	"portions":  true,
+}
+
+// Returns which copyright marker the notice starts with, a misspelled
+// Copyright is returned as it was written
+func noticeMarker(words []TaggedWord) string {
+	for i, word := range words {
+		switch {
+		case strings.ToLower(word.Text) == "copyright":
+			return "Copyright"
+		case strings.TrimSuffix(strings.ToLower(word.Text), ".") == "copr":
+			return "Copr."
+		case strings.Contains(word.Text, "©"):
+			return "©"
+		case word.Text == "(" && i+2 < len(words) &&
+			strings.ToLower(words[i+1].Text) == "c" && words[i+2].Text == ")":
+			return "(c)"
+		case isFuzzyCopyright(word.Text):
+			return word.Text
+		}
+	}
+	return ""
+}
+
+// Returns every number in the notice that looks like a year
+func noticeYears(words []TaggedWord) []int {
+	var years = make([]int, 0)
+	for _, word := range words {
+		if word.Tag != "cd" || len(word.Text) != 4 {
+			continue
+		}
+		year, err := strconv.Atoi(word.Text)
+		if err != nil {
+			continue
+		}
+		years = append(years, year)
+	}
+	return years
+}
+
+// Returns the runs of proper nouns in the notice, each run is one holder.
+// A comma only splits a holder when what follows is not a corporate ending.
+// The holder text is taken from the input so the spacing is the original.
+// Nothing from "All rights reserved" on is a holder.
+func noticeHolders(inBytes []byte, words []TaggedWord) []string {
+	var holders = make([]string, 0)
+	words = words[:rightsReserved(words)]
+
+	runStart := -1
+	runEnd := -1
+	flush := func() {
+		if runStart >= 0 {
+			end := words[runEnd].End
+			// the tagger keeps the period ending a sentence on the name
+			last := words[runEnd].Text
+			if strings.HasSuffix(last, ".") && len(last) > 2 && !isCorporateSuffix(words[runEnd]) {
+				end--
+			}
+			holders = append(holders, string(inBytes[words[runStart].Start:end]))
+		}
+		runStart = -1
+		runEnd = -1
+	}
+
+	for i, word := range words {
+		switch {
+		case word.Tag == "np" && !noticeKeywords[strings.TrimSuffix(strings.ToLower(word.Text), ".")] &&
+			!isFuzzyCopyright(word.Text):
+			if runStart < 0 {
+				runStart = i
+			}
+			runEnd = i
+		case word.Tag == "," && runStart >= 0 && i+1 < len(words) && isCorporateSuffix(words[i+1]):
+			// keep going, the suffix is added on the next word
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	return holders
+}
+
+// Returns the index of the All in "All rights reserved", or len(words)
+// when the notice does not have it
+func rightsReserved(words []TaggedWord) int {
+	for i := 0; i+2 < len(words); i++ {
+		if strings.ToLower(words[i].Text) == "all" &&
+			strings.ToLower(words[i+1].Text) == "rights" &&
+			strings.ToLower(words[i+2].Text) == "reserved" {
+			return i
+		}
+	}
+	return len(words)
+}
+
+// returns true if the word is something like Inc. or Ltd
+func isCorporateSuffix(word TaggedWord) bool {
+	return corporateSuffixes[strings.Trim(strings.ToLower(word.Text), ".")]
+}
@@ -0,0 +1,200 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Unit tests for the structured notice results
+
+package tagger
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractNotices(t *testing.T) {
+	type NoticeTest struct {
+		Prefix  string // the notice text must start with this
+		Marker  string
+		Years   []int
+		Holders []string
+		Text    string
+	}
+
+	tests := []NoticeTest{
+		{
+			Prefix:  "Copyright (c) 2007, 2008 Alastair Houghton",
+			Marker:  "Copyright",
+			Years:   []int{2007, 2008},
+			Holders: []string{"Alastair Houghton"},
+			Text:    "Copyright (c) 2007, 2008 Alastair Houghton",
+		},
+		{
+			Prefix:  "© 2001-2014 Python Software",
+			Marker:  "©",
+			Years:   []int{2001, 2014},
+			Holders: []string{"Python Software Foundation"},
+			Text:    " © 2001-2014 Python Software Foundation",
+		},
+		{
+			Prefix:  "\\(co Exablox  and Pixar     2018",
+			Marker:  "(c)",
+			Years:   []int{2018},
+			Holders: []string{"Exablox", "Pixar"},
+			Text:    "some stuff here. \\(co Exablox  and Pixar     2018 with the Datto corp.",
+		},
+	}
+
+	for i, test := range tests {
+		notices := copyrightTagger.ExtractNotices([]byte(test.Text))
+		if len(notices) != 1 {
+			t.Errorf("Test %d: expected 1 notice got %d", i, len(notices))
+			continue
+		}
+		notice := notices[0]
+		if !strings.HasPrefix(notice.Text, test.Prefix) {
+			t.Errorf("Test %d: expected text starting with %q got %q", i, test.Prefix, notice.Text)
+		}
+		if notice.Text != test.Text[notice.Start:notice.End] {
+			t.Errorf("Test %d: text %q does not match span [%d, %d]", i, notice.Text, notice.Start, notice.End)
+		}
+		if notice.Marker != test.Marker {
+			t.Errorf("Test %d: expected marker %q got %q", i, test.Marker, notice.Marker)
+		}
+		if !reflect.DeepEqual(notice.Years, test.Years) {
+			t.Errorf("Test %d: expected years %v got %v", i, test.Years, notice.Years)
+		}
+		for _, holder := range test.Holders {
+			found := false
+			for _, got := range notice.Holders {
+				found = found || got == holder
+			}
+			if !found {
+				t.Errorf("Test %d: expected holder %q in %q", i, holder, notice.Holders)
+			}
+		}
+	}
+
+	if notices := copyrightTagger.ExtractNotices([]byte("Fetched %sB in %s (%sB/s)\n")); len(notices) != 0 {
+		t.Errorf("expected no notices got %d", len(notices))
+	}
+}
+
+// the period ending the sentence is not part of the holder unless it
+// belongs to a corporate ending
+func TestNoticeHolderPeriod(t *testing.T) {
+	type HolderTest struct {
+		Holders []string
+		Text    string
+	}
+
+	tests := []HolderTest{
+		{Holders: []string{"Jane Doe"}, Text: "(c) 2010 by Jane Doe.\n"},
+		{Holders: []string{"Go Authors"}, Text: "// Copyright 2009 The Go Authors.\n"},
+		{Holders: []string{"Acme Corp."}, Text: "Copyright 2010 Acme Corp.\n"},
+		{Holders: []string{"Foo, Inc."}, Text: "Copyright 2010 Foo, Inc.\n"},
+	}
+
+	for i, test := range tests {
+		notices := copyrightTagger.ExtractNotices([]byte(test.Text))
+		if len(notices) != 1 || !reflect.DeepEqual(notices[0].Holders, test.Holders) {
+			t.Errorf("Test %d: expected holders %q got %v", i, test.Holders, notices)
+		}
+	}
+}
+
+func TestNoticeForms(t *testing.T) {
+	type FormTest struct {
+		Expected string // the whole notice text
+		Marker   string
+		Text     string
+	}
+
+	tests := []FormTest{
+		// Copr. is short for copyright
+		{
+			Expected: "Copr. 1998 IBM",
+			Marker:   "Copr.",
+			Text:     "Licensed Materials Copr. 1998 IBM",
+		},
+		// (C) with no copyright in front of it
+		{
+			Expected: "(C) 2004 Acme",
+			Marker:   "(c)",
+			Text:     "(C) 2004 Acme",
+		},
+		// Portions stays with the notice
+		{
+			Expected: "Portions Copyright 2001 Netscape",
+			Marker:   "Copyright",
+			Text:     "Portions Copyright 2001 Netscape",
+		},
+		// copyright and the © symbol are one notice, by does not end it
+		{
+			Expected: "Copyright © 2010 by Jane Doe",
+			Marker:   "Copyright",
+			Text:     "Copyright © 2010 by Jane Doe",
+		},
+		// All rights reserved is part of the notice
+		{
+			Expected: "Copyright 2012 Google Inc. All rights reserved.",
+			Marker:   "Copyright",
+			Text:     "// Copyright 2012 Google Inc. All rights reserved.\n// Use of this source code",
+		},
+		{
+			Expected: "Copyright (c) 2015 Eric Knapik, All Rights Reserved",
+			Marker:   "Copyright",
+			Text:     "Copyright (c) 2015 Eric Knapik, All Rights Reserved\n\nRedistribution and use",
+		},
+	}
+
+	for i, test := range tests {
+		notices := copyrightTagger.ExtractNotices([]byte(test.Text))
+		if len(notices) != 1 {
+			t.Errorf("Test %d: expected 1 notice got %d", i, len(notices))
+			continue
+		}
+		if notices[0].Text != test.Expected {
+			t.Errorf("Test %d: expected %q got %q", i, test.Expected, notices[0].Text)
+		}
+		if notices[0].Marker != test.Marker {
+			t.Errorf("Test %d: expected marker %q got %q", i, test.Marker, notices[0].Marker)
+		}
+		for _, holder := range notices[0].Holders {
+			if strings.Contains(strings.ToLower(holder), "reserved") {
+				t.Errorf("Test %d: %q is not a holder", i, holder)
+			}
+		}
+	}
+
+	// none of the words are a notice without copyright
+	for _, text := range []string{"All rights reserved.", "portions of the Software"} {
+		if copyrightTagger.Match([]byte(text)) {
+			t.Errorf("expected no notice in %q", text)
+		}
+	}
+}
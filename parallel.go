@@ -0,0 +1,334 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Scanning a big tree one file at a time leaves every core but one idle.
+// A ParallelScanner hands the files to a pool of workers sharing one
+// Tagger, which is only read once New has built it. Files with the same
+// contents, by SHA-256, are scanned once and the later ones get the
+// notices of the first. Results come back in the order the files were
+// added when Ordered is set, the same results ScanFile gives one file at
+// a time, otherwise as soon as they are done.
+
+package tagger
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"runtime"
+	"sync"
+)
+
+// how many files per worker can be waiting to be scanned or reported
+const scanBacklog int = 4
+
+// Scans files for notices, see NewParallelScanner
+type ParallelScanner struct {
+	Tagger   *Tagger
+	Workers  int  // how many files are scanned at once, runtime.NumCPU() below 1
+	Ordered  bool // report results in the order the files were added
+	Binary   bool // scan the strings of ELF, PE and Mach-O files
+	Comments bool // only look in the comments of source files
+
+	// called after each file is reported, from the goroutine that
+	// called Scan
+	Progress func(progress ScanProgress)
+}
+
+// The notices found in one file
+type ScanResult struct {
+	Path    string         `json:"path"`
+	Notices []Notice       `json:"notices"`
+	Binary  []BinaryNotice `json:"binary,omitempty"` // when the file is an executable
+	// the path of the first file added with the same contents, which the
+	// notices were found in and copied from, "" when this file was scanned
+	Duplicate string `json:"duplicate,omitempty"`
+}
+
+// How far a scan has got
+type ScanProgress struct {
+	Files      int    // files reported so far
+	Duplicates int    // of them the ones not scanned since they were seen before
+	Bytes      int64  // the size of the files reported
+	Path       string // the file reported last
+}
+
+// Returns a ParallelScanner for the tagger with a worker for every CPU
+func (copyrightTagger *Tagger) NewParallelScanner() *ParallelScanner {
+	return &ParallelScanner{Tagger: copyrightTagger, Workers: runtime.NumCPU()}
+}
+
+// Returns true when the data looks binary, a zero byte near the start
+// that is not part of UTF-16 text
+func IsBinary(data []byte) bool {
+	sniff := data
+	if len(sniff) > utf16SampleSize {
+		sniff = sniff[:utf16SampleSize]
+	}
+	if bytes.IndexByte(sniff, 0) < 0 {
+		return false
+	}
+	encoding := DetectEncoding(data)
+	return encoding != UTF16LE && encoding != UTF16BE
+}
+
+// Scans one file the way Scan does, without looking for duplicates.
+// Text in any encoding DetectEncoding knows is scanned, the offsets of
+// the notices and of their positions are offsets into data. Binary files are skipped unless
+// Binary is set and they are executables.
+func (scanner *ParallelScanner) ScanFile(path string, data []byte) ScanResult {
+	result := ScanResult{Path: path, Notices: make([]Notice, 0)}
+	if IsBinary(data) {
+		if scanner.Binary {
+			if notices, err := scanner.Tagger.ExtractBinaryNotices(bytes.NewReader(data)); err == nil {
+				result.Binary = notices
+			}
+		}
+		return result
+	}
+
+	transcoded := Transcode(data)
+	if scanner.Comments {
+		result.Notices = scanner.Tagger.ExtractCommentNotices(path, transcoded.Text)
+	} else {
+		result.Notices = scanner.Tagger.ExtractNotices(transcoded.Text)
+	}
+	for i := range result.Notices {
+		notice := &result.Notices[i]
+		notice.Start = transcoded.Offset(notice.Start)
+		notice.End = transcoded.Offset(notice.End)
+		notice.StartPos = transcoded.position(notice.StartPos)
+		notice.EndPos = transcoded.position(notice.EndPos)
+		for j := range notice.Words {
+			notice.Words[j].Start = transcoded.Offset(notice.Words[j].Start)
+			notice.Words[j].End = transcoded.Offset(notice.Words[j].End)
+		}
+	}
+	return result
+}
+
+// One file waiting for a worker
+type scanJob struct {
+	seq    int
+	path   string
+	data   []byte
+	result ScanResult
+	// the first file added with the same contents, which this job scans
+	// when owner is set and waits for otherwise
+	first *scanSeen
+	owner bool
+}
+
+// The first file added with some contents, later ones wait on done for
+// its result
+type scanSeen struct {
+	done   chan struct{}
+	result ScanResult
+}
+
+// Scans the files files adds, calling results with the result of each.
+// files is called once and should call add for every file, add returns
+// an error once the scan is stopping and files should then return it.
+// Scanning stops at the first error files or results returns, or when
+// the context is done, and that error is returned.
+func (scanner *ParallelScanner) Scan(ctx context.Context, files func(add func(path string, data []byte) error) error, results func(result ScanResult) error) error {
+	workers := scanner.Workers
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan scanJob)
+	done := make(chan scanJob)
+	// a slot is held from when a file is added until it is reported
+	slots := make(chan struct{}, scanBacklog*workers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				job.result = scanner.scanOnce(job)
+				done <- job
+			}
+		}()
+	}
+
+	produced := make(chan error, 1)
+	go func() {
+		seq := 0
+		// only the producer touches seen so the first file added with
+		// some contents is always the one scanned
+		seen := make(map[string]*scanSeen)
+		err := files(func(path string, data []byte) error {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			job := scanJob{seq: seq, path: path, data: data}
+			key := scanner.seenKey(path, data)
+			job.first = seen[key]
+			if job.first == nil {
+				job.first = &scanSeen{done: make(chan struct{})}
+				job.owner = true
+			}
+			select {
+			case jobs <- job:
+				if job.owner {
+					seen[key] = job.first
+				}
+				seq++
+				return nil
+			case <-ctx.Done():
+				<-slots
+				return ctx.Err()
+			}
+		})
+		close(jobs)
+		if err != nil {
+			cancel()
+		}
+		produced <- err
+	}()
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	var progress ScanProgress
+	var resultsErr error
+	report := func(job scanJob) {
+		<-slots
+		if resultsErr != nil || ctx.Err() != nil {
+			return
+		}
+		if resultsErr = results(job.result); resultsErr != nil {
+			cancel()
+			return
+		}
+		progress.Files++
+		progress.Bytes += int64(len(job.data))
+		if job.result.Duplicate != "" {
+			progress.Duplicates++
+		}
+		progress.Path = job.path
+		if scanner.Progress != nil {
+			scanner.Progress(progress)
+		}
+	}
+
+	// results that came back before the ones added earlier
+	waiting := make(map[int]scanJob)
+	next := 0
+	for job := range done {
+		if !scanner.Ordered {
+			report(job)
+			continue
+		}
+		waiting[job.seq] = job
+		for job, ok := waiting[next]; ok; job, ok = waiting[next] {
+			delete(waiting, next)
+			next++
+			report(job)
+		}
+	}
+
+	// files has returned by now, jobs is closed before done is
+	filesErr := <-produced
+	if resultsErr != nil {
+		return resultsErr
+	}
+	if filesErr != nil {
+		return filesErr
+	}
+	return ctx.Err()
+}
+
+// The key files with the same contents share, the comments depend on the
+// kind of file as well when they are scanned
+func (scanner *ParallelScanner) seenKey(path string, data []byte) string {
+	sum := sha256.Sum256(data)
+	key := string(sum[:])
+	if scanner.Comments {
+		if style := CommentStyleFor(path, data); style != nil {
+			key += style.Name
+		}
+	}
+	return key
+}
+
+// Scans the file of the job unless it has the same contents as a file
+// added before it, then that file's result is waited for and copied
+func (scanner *ParallelScanner) scanOnce(job scanJob) ScanResult {
+	first := job.first
+	if !job.owner {
+		// the first file was handed to a worker before this one so it
+		// is being scanned and not waiting on anything
+		<-first.done
+		result := copyScanResult(first.result)
+		result.Duplicate = result.Path
+		result.Path = job.path
+		return result
+	}
+	first.result = scanner.ScanFile(job.path, job.data)
+	close(first.done)
+	return first.result
+}
+
+// Copies the result so a duplicate shares none of its slices with the
+// result of the file it duplicates
+func copyScanResult(result ScanResult) ScanResult {
+	if result.Notices != nil {
+		notices := make([]Notice, len(result.Notices))
+		for i, notice := range result.Notices {
+			notices[i] = copyNotice(notice)
+		}
+		result.Notices = notices
+	}
+	if result.Binary != nil {
+		binary := make([]BinaryNotice, len(result.Binary))
+		for i, notice := range result.Binary {
+			binary[i] = notice
+			binary[i].Notice = copyNotice(notice.Notice)
+		}
+		result.Binary = binary
+	}
+	return result
+}
+
+func copyNotice(notice Notice) Notice {
+	notice.Years = append(notice.Years[:0:0], notice.Years...)
+	notice.Holders = append(notice.Holders[:0:0], notice.Holders...)
+	notice.Words = append(notice.Words[:0:0], notice.Words...)
+	notice.YearRanges.Ranges = append(notice.YearRanges.Ranges[:0:0], notice.YearRanges.Ranges...)
+	notice.YearRanges.Problems = append(notice.YearRanges.Problems[:0:0], notice.YearRanges.Problems...)
+	return notice
+}
@@ -0,0 +1,191 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Unit tests for scanning files in parallel
+
+package tagger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// files with a notice every few and the same contents every so often
+func parallelFiles(count int) ([]string, [][]byte) {
+	var paths []string
+	var files [][]byte
+	for i := 0; i < count; i++ {
+		paths = append(paths, fmt.Sprintf("dir/file%03d.c", i))
+		switch i % 5 {
+		case 0:
+			files = append(files, []byte(fmt.Sprintf("// Copyright %d Jane Doe\nint x;\n", 1990+i)))
+		case 1:
+			files = append(files, []byte("// Copyright 2010 Shared Holder\n"))
+		default:
+			files = append(files, []byte(fmt.Sprintf("int x%d;\n", i)))
+		}
+	}
+	return paths, files
+}
+
+func TestParallelScanner(t *testing.T) {
+	paths, files := parallelFiles(60)
+	addAll := func(add func(path string, data []byte) error) error {
+		for i := range paths {
+			if err := add(paths[i], files[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	scanner := copyrightTagger.NewParallelScanner()
+	var sequential []ScanResult
+	for i := range paths {
+		sequential = append(sequential, scanner.ScanFile(paths[i], files[i]))
+	}
+
+	for _, workers := range []int{1, 3, 8} {
+		scanner.Workers = workers
+		scanner.Ordered = true
+		var last ScanProgress
+		scanner.Progress = func(progress ScanProgress) { last = progress }
+
+		var ordered []ScanResult
+		err := scanner.Scan(context.Background(), addAll, func(result ScanResult) error {
+			ordered = append(ordered, result)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Workers %d: unexpected error %v", workers, err)
+		}
+		if len(ordered) != len(sequential) {
+			t.Fatalf("Workers %d: expected %d results got %d", workers, len(sequential), len(ordered))
+		}
+		for i := range ordered {
+			if ordered[i].Path != sequential[i].Path || !reflect.DeepEqual(ordered[i].Notices, sequential[i].Notices) {
+				t.Errorf("Workers %d: result %d differs from the sequential scan: %v and %v", workers, i, ordered[i], sequential[i])
+			}
+		}
+		// file001.c is the first of the shared files, 11 more follow it
+		if last.Files != len(paths) || last.Duplicates != 11 {
+			t.Errorf("Workers %d: expected %d files and 11 duplicates got %+v", workers, len(paths), last)
+		}
+		if ordered[6].Duplicate != "dir/file001.c" {
+			t.Errorf("Workers %d: expected file006.c to be a duplicate of file001.c got %q", workers, ordered[6].Duplicate)
+		}
+		// a duplicate has its own copy of the notices
+		if len(ordered[6].Notices) > 0 && len(ordered[6].Notices[0].Holders) > 0 {
+			ordered[6].Notices[0].Holders[0] = "changed"
+			if ordered[1].Notices[0].Holders[0] == "changed" {
+				t.Errorf("Workers %d: expected file006.c not to share holders with file001.c", workers)
+			}
+		}
+
+		scanner.Ordered = false
+		var unordered []string
+		err = scanner.Scan(context.Background(), addAll, func(result ScanResult) error {
+			unordered = append(unordered, result.Path)
+			return nil
+		})
+		sort.Strings(unordered)
+		if err != nil || !reflect.DeepEqual(unordered, paths) {
+			t.Errorf("Workers %d: expected every file unordered got %d %v", workers, len(unordered), err)
+		}
+	}
+}
+
+// the offsets and positions of a notice in a UTF-16 file are in its bytes
+func TestScanFileEncoded(t *testing.T) {
+	scanner := copyrightTagger.NewParallelScanner()
+	raw := mkUTF16("int x;\n// Copyright 2010 Jane Doe\n", false, true)
+	result := scanner.ScanFile("a.c", raw)
+	if len(result.Notices) != 1 {
+		t.Fatalf("expected one notice got %v", result.Notices)
+	}
+	notice := result.Notices[0]
+	// the BOM, then the 10 characters before Copyright at two bytes each
+	if notice.Start != 2+2*10 || notice.StartPos.Offset != notice.Start || notice.EndPos.Offset != notice.End {
+		t.Errorf("expected the notice and its positions at byte 22 got %d %+v %+v", notice.Start, notice.StartPos, notice.EndPos)
+	}
+	if notice.StartPos.Line != 2 || notice.StartPos.Column != 7 || notice.StartPos.Column16 != 4 {
+		t.Errorf("expected line 2 byte column 7 and column16 4 got %+v", notice.StartPos)
+	}
+}
+
+func TestParallelScannerStops(t *testing.T) {
+	paths, files := parallelFiles(100)
+	scanner := copyrightTagger.NewParallelScanner()
+	scanner.Workers = 4
+	scanner.Ordered = true
+
+	stop := errors.New("stop")
+	reported := 0
+	err := scanner.Scan(context.Background(), func(add func(path string, data []byte) error) error {
+		for i := range paths {
+			if err := add(paths[i], files[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, func(result ScanResult) error {
+		reported++
+		if reported == 10 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || reported != 10 {
+		t.Errorf("expected to stop after 10 results with %v got %d %v", stop, reported, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = scanner.Scan(ctx, func(add func(path string, data []byte) error) error {
+		return add(paths[0], files[0])
+	}, func(result ScanResult) error {
+		return nil
+	})
+	if err != context.Canceled {
+		t.Errorf("expected %v got %v", context.Canceled, err)
+	}
+
+	failed := errors.New("walk failed")
+	err = scanner.Scan(context.Background(), func(add func(path string, data []byte) error) error {
+		return failed
+	}, func(result ScanResult) error {
+		return nil
+	})
+	if err != failed {
+		t.Errorf("expected %v got %v", failed, err)
+	}
+}
@@ -0,0 +1,608 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// This file is a small regular expression engine that works on tagged
+// words instead of characters. It is for pulling things other than
+// copyright notices out of tagged text, "licensed under the X license" or
+// "written by NP", without writing another DFA table by hand.
+//
+// A pattern is a sequence of word matchers:
+//
+//	[tag=np]             a word tagged np, tag=,|-- is a comma or a dash
+//	[tag!=np]            a word not tagged np
+//	[word=GPL|LGPL]      the word is exactly GPL or LGPL, \| \& and \]
+//	                     are a literal |, & and ]
+//	[word~/^copy/i]      the word matches the Go regular expression,
+//	                     the i flag ignores case
+//	[tag=np & word~/^A/] every condition must hold
+//	"license"            the word, ignoring case
+//	.                    any word
+//
+// Matchers are combined the same way as regular expressions, (x) captures,
+// (?:x) only groups, (?<name>x) captures with a name, x|y, x?, x* and x+.
+// The pattern is compiled to an NFA and run over a slice of TaggedWord,
+// matches are leftmost-longest and do not overlap.
+
+package tagger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// A compiled tag pattern, safe to use from more than one goroutine
+type TagPattern struct {
+	source   string
+	prog     []patternInst
+	numCaps  int      // number of capture groups, group 0 is the whole match
+	capNames []string // capNames[i] is the name of group i or ""
+}
+
+// One match of a tag pattern. Groups[2*i] and Groups[2*i+1] are the word
+// indices of the start and end of group i, like regexp's submatch indices.
+// A group that did not take part in the match is -1, -1.
+type TagMatch struct {
+	Groups []int
+}
+
+// the instructions of the compiled NFA
+const (
+	instWord  int = iota // match one word against a matcher and move on
+	instSplit            // try x first then y
+	instJump             // go to x
+	instSave             // remember the current word index in slot n
+	instMatch            // the pattern matched
+)
+
+type patternInst struct {
+	op    int
+	x, y  int
+	n     int
+	match *wordMatcher
+}
+
+// the conditions one word has to meet, all of them must hold
+type wordMatcher struct {
+	conds []wordCond
+}
+
+type wordCond struct {
+	onTag  bool // look at the tag instead of the word
+	negate bool
+	fold   bool     // compare ignoring case
+	values []string // any one of these is a match
+	re     *regexp.Regexp
+}
+
+// Compiles a tag pattern, see the top of this file for the syntax
+func CompileTagPattern(pattern string) (*TagPattern, error) {
+	parser := patternParser{src: []rune(pattern), names: []string{""}}
+	tree, err := parser.parseAlt()
+	if err == nil && parser.pos < len(parser.src) {
+		err = parser.errorf("unexpected %q", parser.src[parser.pos])
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	compiled := &TagPattern{source: pattern, numCaps: len(parser.names), capNames: parser.names}
+	// the whole match is group 0
+	compiled.emit(patternInst{op: instSave, n: 0})
+	compiled.compile(tree)
+	compiled.emit(patternInst{op: instSave, n: 1})
+	compiled.emit(patternInst{op: instMatch})
+	return compiled, nil
+}
+
+// Like CompileTagPattern but panics if the pattern is wrong, for patterns
+// written into the code
+func MustCompileTagPattern(pattern string) *TagPattern {
+	compiled, err := CompileTagPattern(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return compiled
+}
+
+// Returns the pattern the TagPattern was compiled from
+func (pattern *TagPattern) String() string {
+	return pattern.source
+}
+
+// Returns the names of the capture groups, the first is the whole match
+// and unnamed groups are the empty string
+func (pattern *TagPattern) SubexpNames() []string {
+	return append([]string(nil), pattern.capNames...)
+}
+
+// Returns the first match in the words and whether there was one
+func (pattern *TagPattern) Find(words []TaggedWord) (TagMatch, bool) {
+	matches := pattern.find(words, 1)
+	if len(matches) == 0 {
+		return TagMatch{}, false
+	}
+	return matches[0], true
+}
+
+// Returns every match in the words, leftmost first and longest at
+// each position. Matches do not overlap.
+func (pattern *TagPattern) FindAll(words []TaggedWord) []TagMatch {
+	return pattern.find(words, -1)
+}
+
+// Returns the words of a group of the match, nil if the group did not match
+func (match TagMatch) Words(words []TaggedWord, group int) []TaggedWord {
+	if 2*group+1 >= len(match.Groups) || match.Groups[2*group] < 0 {
+		return nil
+	}
+	return words[match.Groups[2*group]:match.Groups[2*group+1]]
+}
+
+// Returns the byte offsets in the tagged input that a group of the match
+// covers. ok is false if the group did not match or matched no words.
+func (match TagMatch) Span(words []TaggedWord, group int) (start int, end int, ok bool) {
+	matched := match.Words(words, group)
+	if len(matched) == 0 {
+		return 0, 0, false
+	}
+	return matched[0].Start, matched[len(matched)-1].End, true
+}
+
+// Runs the NFA from each word in turn keeping the longest match that
+// starts at the leftmost word, then carries on after that match
+func (pattern *TagPattern) find(words []TaggedWord, limit int) []TagMatch {
+	var matches = make([]TagMatch, 0)
+	for start := 0; start <= len(words) && (limit < 0 || len(matches) < limit); {
+		caps, ok := pattern.longestAt(words, start)
+		if !ok {
+			start++
+			continue
+		}
+		matches = append(matches, TagMatch{Groups: caps})
+		if caps[1] > start {
+			start = caps[1]
+		} else {
+			start++
+		}
+	}
+	return matches
+}
+
+// a thread of the NFA simulation, where it is and what it captured
+type patternThread struct {
+	pc   int
+	caps []int
+}
+
+// Simulates the NFA anchored at start, all threads move one word at a
+// time so this is linear in the words left times the pattern size
+func (pattern *TagPattern) longestAt(words []TaggedWord, start int) ([]int, bool) {
+	var best []int
+	caps := make([]int, 2*pattern.numCaps)
+	for i := range caps {
+		caps[i] = -1
+	}
+
+	current := pattern.addThread(nil, make([]bool, len(pattern.prog)), patternThread{pc: 0, caps: caps}, start)
+	for pos := start; len(current) > 0; pos++ {
+		var next []patternThread
+		visited := make([]bool, len(pattern.prog))
+		for _, thread := range current {
+			inst := pattern.prog[thread.pc]
+			switch inst.op {
+			case instMatch:
+				// the earliest thread to match at a longer end wins
+				if best == nil || thread.caps[1] > best[1] {
+					best = thread.caps
+				}
+			case instWord:
+				if pos < len(words) && inst.match.matches(words[pos]) {
+					next = pattern.addThread(next, visited, patternThread{pc: inst.x, caps: thread.caps}, pos+1)
+				}
+			}
+		}
+		if pos >= len(words) {
+			break
+		}
+		current = next
+	}
+	return best, best != nil
+}
+
+// Follows the jumps, splits and saves from a thread so only threads
+// sitting on a word or a match are kept. Visited stops loops like (x*)*.
+func (pattern *TagPattern) addThread(threads []patternThread, visited []bool, thread patternThread, pos int) []patternThread {
+	if visited[thread.pc] {
+		return threads
+	}
+	visited[thread.pc] = true
+
+	inst := pattern.prog[thread.pc]
+	switch inst.op {
+	case instJump:
+		return pattern.addThread(threads, visited, patternThread{pc: inst.x, caps: thread.caps}, pos)
+	case instSplit:
+		threads = pattern.addThread(threads, visited, patternThread{pc: inst.x, caps: thread.caps}, pos)
+		return pattern.addThread(threads, visited, patternThread{pc: inst.y, caps: thread.caps}, pos)
+	case instSave:
+		caps := append([]int(nil), thread.caps...)
+		caps[inst.n] = pos
+		return pattern.addThread(threads, visited, patternThread{pc: inst.x, caps: caps}, pos)
+	}
+	return append(threads, thread)
+}
+
+// Returns true if the word meets every condition
+func (matcher *wordMatcher) matches(word TaggedWord) bool {
+	for _, cond := range matcher.conds {
+		if cond.matches(word) == cond.negate {
+			return false
+		}
+	}
+	return true
+}
+
+func (cond wordCond) matches(word TaggedWord) bool {
+	text := word.Text
+	if cond.onTag {
+		text = word.Tag
+	}
+	if cond.re != nil {
+		return cond.re.MatchString(text)
+	}
+	for _, value := range cond.values {
+		if text == value || (cond.fold && strings.EqualFold(text, value)) {
+			return true
+		}
+	}
+	return false
+}
+
+// The parsed pattern before it is compiled
+type patternNode struct {
+	kind     int // one of the node kinds below
+	match    *wordMatcher
+	children []*patternNode
+	capture  int // the group number of a capturing group
+}
+
+const (
+	nodeWord    int = iota // a single word matcher
+	nodeConcat             // children one after the other
+	nodeAlt                // any one of the children
+	nodeStar               // the child zero or more times
+	nodePlus               // the child one or more times
+	nodeQuest              // the child zero or one time
+	nodeCapture            // the child captured as a group
+)
+
+func (pattern *TagPattern) emit(inst patternInst) int {
+	if inst.op == instSave {
+		inst.x = len(pattern.prog) + 1
+	}
+	pattern.prog = append(pattern.prog, inst)
+	return len(pattern.prog) - 1
+}
+
+// Thompson's construction, each node is emitted so it falls through
+// to whatever is emitted after it
+func (pattern *TagPattern) compile(node *patternNode) {
+	switch node.kind {
+	case nodeWord:
+		pc := pattern.emit(patternInst{op: instWord, match: node.match})
+		pattern.prog[pc].x = pc + 1
+	case nodeConcat:
+		for _, child := range node.children {
+			pattern.compile(child)
+		}
+	case nodeCapture:
+		pattern.emit(patternInst{op: instSave, n: 2 * node.capture})
+		pattern.compile(node.children[0])
+		pattern.emit(patternInst{op: instSave, n: 2*node.capture + 1})
+	case nodeAlt:
+		var jumps []int
+		for i, child := range node.children {
+			if i == len(node.children)-1 {
+				pattern.compile(child)
+				break
+			}
+			split := pattern.emit(patternInst{op: instSplit})
+			pattern.prog[split].x = split + 1
+			pattern.compile(child)
+			jumps = append(jumps, pattern.emit(patternInst{op: instJump}))
+			pattern.prog[split].y = len(pattern.prog)
+		}
+		for _, jump := range jumps {
+			pattern.prog[jump].x = len(pattern.prog)
+		}
+	case nodeQuest:
+		split := pattern.emit(patternInst{op: instSplit})
+		pattern.prog[split].x = split + 1
+		pattern.compile(node.children[0])
+		pattern.prog[split].y = len(pattern.prog)
+	case nodeStar:
+		split := pattern.emit(patternInst{op: instSplit})
+		pattern.prog[split].x = split + 1
+		pattern.compile(node.children[0])
+		pattern.emit(patternInst{op: instJump, x: split})
+		pattern.prog[split].y = len(pattern.prog)
+	case nodePlus:
+		start := len(pattern.prog)
+		pattern.compile(node.children[0])
+		pattern.emit(patternInst{op: instSplit, x: start, y: len(pattern.prog) + 1})
+	}
+}
+
+// A recursive descent parser for the pattern syntax
+type patternParser struct {
+	src   []rune
+	pos   int
+	names []string // one entry per capture group, group 0 is the match
+}
+
+func (parser *patternParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("tag pattern at %d: %s", parser.pos, fmt.Sprintf(format, args...))
+}
+
+func (parser *patternParser) skipSpace() {
+	for parser.pos < len(parser.src) && unicode.IsSpace(parser.src[parser.pos]) {
+		parser.pos++
+	}
+}
+
+// returns the next rune that is not a space without using it, 0 at the end
+func (parser *patternParser) peek() rune {
+	parser.skipSpace()
+	if parser.pos >= len(parser.src) {
+		return 0
+	}
+	return parser.src[parser.pos]
+}
+
+// alt := seq ('|' seq)*
+func (parser *patternParser) parseAlt() (*patternNode, error) {
+	var children []*patternNode
+	for {
+		seq, err := parser.parseSeq()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, seq)
+		if parser.peek() != '|' {
+			break
+		}
+		parser.pos++
+	}
+	if len(children) == 1 {
+		return children[0], nil
+	}
+	return &patternNode{kind: nodeAlt, children: children}, nil
+}
+
+// seq := (atom quantifier*)*
+func (parser *patternParser) parseSeq() (*patternNode, error) {
+	seq := &patternNode{kind: nodeConcat}
+	for {
+		r := parser.peek()
+		if r == 0 || r == '|' || r == ')' {
+			break
+		}
+		atom, err := parser.parseAtom()
+		if err != nil {
+			return nil, err
+		}
+		for {
+			quant := parser.peek()
+			if quant == '?' {
+				atom = &patternNode{kind: nodeQuest, children: []*patternNode{atom}}
+			} else if quant == '*' {
+				atom = &patternNode{kind: nodeStar, children: []*patternNode{atom}}
+			} else if quant == '+' {
+				atom = &patternNode{kind: nodePlus, children: []*patternNode{atom}}
+			} else {
+				break
+			}
+			parser.pos++
+		}
+		seq.children = append(seq.children, atom)
+	}
+	return seq, nil
+}
+
+// atom := '[' conditions ']' | '"' literal '"' | '.' | '(' alt ')'
+func (parser *patternParser) parseAtom() (*patternNode, error) {
+	switch parser.peek() {
+	case '[':
+		parser.pos++
+		matcher, err := parser.parseConditions()
+		if err != nil {
+			return nil, err
+		}
+		return &patternNode{kind: nodeWord, match: matcher}, nil
+	case '"':
+		parser.pos++
+		literal, err := parser.readUntil('"')
+		if err != nil {
+			return nil, err
+		}
+		cond := wordCond{fold: true, values: []string{literal}}
+		return &patternNode{kind: nodeWord, match: &wordMatcher{conds: []wordCond{cond}}}, nil
+	case '.':
+		parser.pos++
+		return &patternNode{kind: nodeWord, match: &wordMatcher{}}, nil
+	case '(':
+		parser.pos++
+		capture := -1
+		if parser.pos+1 < len(parser.src) && parser.src[parser.pos] == '?' && parser.src[parser.pos+1] == ':' {
+			parser.pos += 2
+		} else if parser.pos+1 < len(parser.src) && parser.src[parser.pos] == '?' && parser.src[parser.pos+1] == '<' {
+			parser.pos += 2
+			name, err := parser.readUntil('>')
+			if err != nil {
+				return nil, err
+			}
+			capture = len(parser.names)
+			parser.names = append(parser.names, name)
+		} else {
+			capture = len(parser.names)
+			parser.names = append(parser.names, "")
+		}
+		inner, err := parser.parseAlt()
+		if err != nil {
+			return nil, err
+		}
+		if parser.peek() != ')' {
+			return nil, parser.errorf("missing )")
+		}
+		parser.pos++
+		if capture < 0 {
+			return inner, nil
+		}
+		return &patternNode{kind: nodeCapture, capture: capture, children: []*patternNode{inner}}, nil
+	case 0:
+		return nil, parser.errorf("unexpected end of pattern")
+	}
+	return nil, parser.errorf("unexpected %q", parser.src[parser.pos])
+}
+
+// conditions := condition ('&' condition)* ']'
+// condition := ('word' | 'tag') ('=' | '!=') value ('|' value)*
+//
+//	| ('word' | 'tag') ('~' | '!~') '/' regexp '/' flags
+func (parser *patternParser) parseConditions() (*wordMatcher, error) {
+	matcher := &wordMatcher{}
+	for {
+		parser.skipSpace()
+		start := parser.pos
+		for parser.pos < len(parser.src) && unicode.IsLetter(parser.src[parser.pos]) {
+			parser.pos++
+		}
+		field := string(parser.src[start:parser.pos])
+		if field != "word" && field != "tag" {
+			return nil, parser.errorf("expected word or tag got %q", field)
+		}
+		cond := wordCond{onTag: field == "tag"}
+
+		parser.skipSpace()
+		if parser.pos < len(parser.src) && parser.src[parser.pos] == '!' {
+			cond.negate = true
+			parser.pos++
+		}
+		if parser.pos >= len(parser.src) {
+			return nil, parser.errorf("unexpected end of pattern")
+		}
+		switch parser.src[parser.pos] {
+		case '=':
+			parser.pos++
+			values, err := parser.readValues()
+			if err != nil {
+				return nil, err
+			}
+			cond.values = values
+		case '~':
+			parser.pos++
+			if parser.peek() != '/' {
+				return nil, parser.errorf("expected /regexp/ after ~")
+			}
+			parser.pos++
+			expr, err := parser.readUntil('/')
+			if err != nil {
+				return nil, err
+			}
+			for parser.pos < len(parser.src) && parser.src[parser.pos] == 'i' {
+				expr = "(?i)" + expr
+				parser.pos++
+			}
+			cond.re, err = regexp.Compile(expr)
+			if err != nil {
+				return nil, parser.errorf("%v", err)
+			}
+		default:
+			return nil, parser.errorf("expected = or ~ got %q", parser.src[parser.pos])
+		}
+		matcher.conds = append(matcher.conds, cond)
+
+		switch parser.peek() {
+		case '&':
+			parser.pos++
+		case ']':
+			parser.pos++
+			return matcher, nil
+		default:
+			return nil, parser.errorf("expected & or ]")
+		}
+	}
+}
+
+// reads the values up to the & or ] that ends them, split on |. A
+// backslash escapes the next character, so \| is a literal pipe, and
+// spaces around each value are dropped
+func (parser *patternParser) readValues() ([]string, error) {
+	var values []string
+	var value []rune
+	for parser.pos < len(parser.src) {
+		r := parser.src[parser.pos]
+		if r == '&' || r == ']' {
+			return append(values, strings.TrimSpace(string(value))), nil
+		}
+		if r == '|' {
+			values = append(values, strings.TrimSpace(string(value)))
+			value = nil
+			parser.pos++
+			continue
+		}
+		if r == '\\' && parser.pos+1 < len(parser.src) {
+			parser.pos++
+			r = parser.src[parser.pos]
+		}
+		value = append(value, r)
+		parser.pos++
+	}
+	return nil, parser.errorf("missing ]")
+}
+
+// reads up to the closing rune and uses it, a backslash escapes the
+// closing rune but is otherwise kept so regular expressions still work
+func (parser *patternParser) readUntil(closing rune) (string, error) {
+	var value []rune
+	for parser.pos < len(parser.src) {
+		r := parser.src[parser.pos]
+		parser.pos++
+		if r == closing {
+			return string(value), nil
+		}
+		if r == '\\' && parser.pos < len(parser.src) && parser.src[parser.pos] == closing {
+			r = closing
+			parser.pos++
+		}
+		value = append(value, r)
+	}
+	return "", parser.errorf("missing %q", closing)
+}
@@ -0,0 +1,151 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Unit tests for the tag pattern engine
+
+package tagger
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// builds tagged words from "word/tag word/tag ..." with offsets as if
+// the words were joined by single spaces
+func mkTaggedWords(tagged string) []TaggedWord {
+	var words []TaggedWord
+	offset := 0
+	for _, pair := range strings.Fields(tagged) {
+		split := strings.LastIndex(pair, "/")
+		word := TaggedWord{Text: pair[:split], Tag: pair[split+1:], Start: offset, End: offset + split}
+		words = append(words, word)
+		offset = word.End + 1
+	}
+	return words
+}
+
+func TestTagPattern(t *testing.T) {
+	type PatternTest struct {
+		Expected [][]int // the Groups of every match
+		Pattern  string
+		Words    string
+	}
+
+	notice := `[word~/^copyright$/i] "(" "c" ")"? [tag=cd]+ ([tag=,|--] [tag=cd])* [tag=np]+`
+	tests := []PatternTest{
+		{
+			Expected: [][]int{{0, 10, 7, 9}},
+			Pattern:  notice,
+			Words:    "Copyright/nn (/( C/nn )/) 2007/cd ,/, 2008/cd -/-- 2009/cd Acme/np ./.",
+		},
+		{
+			// without the ) the optional group is skipped, and there is no (
+			Expected: [][]int{},
+			Pattern:  notice,
+			Words:    "Copyright/nn 2007/cd Acme/np",
+		},
+		{
+			Expected: [][]int{{1, 5, 3, 5}},
+			Pattern:  `"written" "by" ([tag=np]+)`,
+			Words:    "was/vb written/vb by/in Eric/np Knapik/np in/in 2015/cd",
+		},
+		{
+			// longest wins over the first alternative
+			Expected: [][]int{{0, 3}},
+			Pattern:  `[tag=np] | [tag=np] [tag=np] [tag=np]`,
+			Words:    "Free/np Software/np Foundation/np",
+		},
+		{
+			// matches do not overlap and carry on after each other
+			Expected: [][]int{{0, 2}, {3, 5}},
+			Pattern:  `[tag=cd] [tag!=cd]`,
+			Words:    "1/cd a/dt 2/cd 3/cd b/dt",
+		},
+		{
+			Expected: [][]int{{2, 8, 5, 7}},
+			Pattern:  `"licensed" "under" "the" (?<license>[tag=np & word!=License]+) [word=license|License]`,
+			Words:    "This/dt is/vb licensed/vb under/in the/dt Apache/np 2.0/np License/np ./.",
+		},
+		{
+			Expected: [][]int{{0, 2, -1, -1}, {2, 3, 2, 3}},
+			Pattern:  `. . | ([tag=sym])`,
+			Words:    "a/nn b/nn ©/sym",
+		},
+		{
+			// an escaped pipe is part of the word, not another value
+			Expected: [][]int{{0, 1}, {2, 3}},
+			Pattern:  `[word=a\|b | c]`,
+			Words:    "a|b/nn a/nn c/nn b/nn",
+		},
+	}
+
+	for i, test := range tests {
+		pattern, err := CompileTagPattern(test.Pattern)
+		if err != nil {
+			t.Errorf("Test %d: could not compile: %v", i, err)
+			continue
+		}
+		var groups = make([][]int, 0)
+		for _, match := range pattern.FindAll(mkTaggedWords(test.Words)) {
+			groups = append(groups, match.Groups)
+		}
+		if !reflect.DeepEqual(groups, test.Expected) {
+			t.Errorf("Test %d: expected %v got %v", i, test.Expected, groups)
+		}
+	}
+
+	pattern := MustCompileTagPattern(`"by" (?<who>[tag=np]+)`)
+	words := mkTaggedWords("written/vb by/in Eric/np Knapik/np")
+	match, ok := pattern.Find(words)
+	if !ok {
+		t.Fatalf("expected a match")
+	}
+	if names := pattern.SubexpNames(); len(names) != 2 || names[1] != "who" {
+		t.Errorf("unexpected group names %q", names)
+	}
+	if start, end, ok := match.Span(words, 1); !ok || start != 11 || end != 22 {
+		t.Errorf("expected the name at [11, 22] got [%d, %d]", start, end)
+	}
+}
+
+func TestTagPatternErrors(t *testing.T) {
+	for i, pattern := range []string{
+		`[tag=np`,
+		`[pos=np]`,
+		`("a"`,
+		`"a`,
+		`[word~/(/]`,
+		`[tag np]`,
+		`)`,
+	} {
+		if _, err := CompileTagPattern(pattern); err == nil {
+			t.Errorf("Test %d: expected an error for %q", i, pattern)
+		}
+	}
+}
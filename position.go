@@ -0,0 +1,106 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Byte offsets are all the tagger needs but editors and CI annotations
+// want lines and columns. Columns are counted both in bytes of UTF-8 and
+// in UTF-16 code units, which is what JavaScript and the Language Server
+// Protocol count in. All the positions asked for are worked out in one
+// pass over the input.
+
+package tagger
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+// A place in the input. Line, Column and Column16 start at 1, Column
+// counts bytes and Column16 UTF-16 code units from the start of the line.
+type Position struct {
+	Offset   int `json:"offset"`
+	Line     int `json:"line"`
+	Column   int `json:"column"`
+	Column16 int `json:"column16"`
+}
+
+// Returns the Position of every byte offset in the input, in the same
+// order as the offsets. The offsets can be in any order, ones before the
+// start or past the end of the input are moved to the start or the end.
+// A \r\n ends a line the same as a \n does.
+func Positions(inBytes []byte, offsets []int) []Position {
+	var positions = make([]Position, len(offsets))
+	order := make([]int, len(offsets))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return offsets[order[a]] < offsets[order[b]] })
+
+	at, line, lineStart, column16 := 0, 1, 0, 1
+	for _, i := range order {
+		offset := offsets[i]
+		if offset < 0 {
+			offset = 0
+		} else if offset > len(inBytes) {
+			offset = len(inBytes)
+		}
+		for at < offset {
+			r, size := utf8.DecodeRune(inBytes[at:])
+			at += size
+			switch {
+			case r == '\n':
+				line++
+				lineStart = at
+				column16 = 1
+			case r >= 0x10000:
+				column16 += 2 // a surrogate pair
+			default:
+				column16++
+			}
+		}
+		positions[i] = Position{
+			Offset:   offset,
+			Line:     line,
+			Column:   offset - lineStart + 1,
+			Column16: column16,
+		}
+	}
+	return positions
+}
+
+// Fills in the StartPos and EndPos of the notices found in the input
+func setPositions(inBytes []byte, notices []Notice) {
+	var offsets = make([]int, 0, 2*len(notices))
+	for _, notice := range notices {
+		offsets = append(offsets, notice.Start, notice.End)
+	}
+	positions := Positions(inBytes, offsets)
+	for i := range notices {
+		notices[i].StartPos = positions[2*i]
+		notices[i].EndPos = positions[2*i+1]
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Unit tests for line and column positions
+
+package tagger
+
+import (
+	"testing"
+)
+
+func TestPositions(t *testing.T) {
+	type PositionTest struct {
+		Expected Position
+		Offset   int
+	}
+
+	// é is two bytes and one UTF-16 unit, 😀 is four bytes and two units
+	text := []byte("héllo\r\n😀 x\nend")
+	tests := []PositionTest{
+		{Expected: Position{Offset: 0, Line: 1, Column: 1, Column16: 1}, Offset: 0},
+		{Expected: Position{Offset: 3, Line: 1, Column: 4, Column16: 3}, Offset: 3},
+		{Expected: Position{Offset: 6, Line: 1, Column: 7, Column16: 6}, Offset: 6},
+		{Expected: Position{Offset: 8, Line: 2, Column: 1, Column16: 1}, Offset: 8},
+		{Expected: Position{Offset: 13, Line: 2, Column: 6, Column16: 4}, Offset: 13},
+		{Expected: Position{Offset: 15, Line: 3, Column: 1, Column16: 1}, Offset: 15},
+		{Expected: Position{Offset: 18, Line: 3, Column: 4, Column16: 4}, Offset: 18},
+		{Expected: Position{Offset: 18, Line: 3, Column: 4, Column16: 4}, Offset: 99},
+		{Expected: Position{Offset: 0, Line: 1, Column: 1, Column16: 1}, Offset: -1},
+	}
+
+	// the offsets are deliberately out of order
+	offsets := make([]int, len(tests))
+	for i, test := range tests {
+		offsets[len(tests)-1-i] = test.Offset
+	}
+	positions := Positions(text, offsets)
+	for i, test := range tests {
+		if got := positions[len(tests)-1-i]; got != test.Expected {
+			t.Errorf("Test %d: expected %+v got %+v", i, test.Expected, got)
+		}
+	}
+}
+
+func TestNoticePositions(t *testing.T) {
+	text := []byte("package main\n\n// 😀 Copyright 2010 Jane Doe\nfunc main() {}\n")
+	notices := copyrightTagger.ExtractNotices(text)
+	if len(notices) != 1 {
+		t.Fatalf("expected one notice got %d", len(notices))
+	}
+	notice := notices[0]
+	if notice.Text != "Copyright 2010 Jane Doe" {
+		t.Errorf("expected the notice to end at Doe got %q", notice.Text)
+	}
+	start := Position{Offset: 22, Line: 3, Column: 9, Column16: 7}
+	end := Position{Offset: 45, Line: 3, Column: 32, Column16: 30}
+	if notice.StartPos != start || notice.EndPos != end {
+		t.Errorf("expected %+v to %+v got %+v to %+v", start, end, notice.StartPos, notice.EndPos)
+	}
+
+	comments := copyrightTagger.ExtractCommentNotices("main.go", text)
+	if len(comments) != 1 || comments[0].StartPos != start || comments[0].EndPos != end {
+		t.Errorf("expected the comment notice at %+v to %+v got %+v", start, end, comments)
+	}
+}
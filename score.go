@@ -0,0 +1,190 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Every notice the scanner keeps gets a Score from 0 to 1 saying how sure
+// the tagger is that it found a real notice. The score adds up how strong
+// the marker is, whether the years make sense, whether someone holds the
+// copyright and how sure the tagger was of the tags, then takes off how
+// much the notice reads like a mention. Notices scoring below the
+// Tagger's MinScore are dropped, SetSensitivity picks a MinScore.
+
+package tagger
+
+import (
+	"strings"
+)
+
+// How many notices the tagger reports, a higher sensitivity finds more
+// notices and more things that are not notices
+type Sensitivity int
+
+const (
+	SensitivityHigh   Sensitivity = iota // every run the DFA keeps, what New does
+	SensitivityMedium                    // drops runs with little beside a marker
+	SensitivityLow                       // only notices with a marker, years and holders
+)
+
+// the MinScore of each sensitivity
+var sensitivityScores = map[Sensitivity]float64{
+	SensitivityHigh:   0,
+	SensitivityMedium: 0.4,
+	SensitivityLow:    0.7,
+}
+
+// how much each part of a notice counts towards its score, they add to 1
+const (
+	scoreMarker float64 = 0.25
+	scoreYears  float64 = 0.3
+	scoreHolder float64 = 0.25
+	scoreTags   float64 = 0.2
+)
+
+// how strong each marker is, © is only ever used for copyright
+var markerStrength = map[string]float64{
+	"©":         1,
+	"(c)":       0.9,
+	"Copyright": 0.7,
+	"Copr.":     0.6,
+}
+
+// the strength of a marker that is not in markerStrength
+const weakMarker float64 = 0.3
+
+// the strength of a misspelled Copyright, see fuzzy.go. It is below
+// weakMarker so a notice that only has a misspelling scores well under
+// the same notice spelled right.
+const fuzzyMarker float64 = 0.1
+
+// how sure the tagger is of a word it had never seen
+const unknownTagConfidence float64 = 0.5
+
+// Returns the MinScore that goes with the sensitivity
+func (sensitivity Sensitivity) MinScore() float64 {
+	return sensitivityScores[sensitivity]
+}
+
+func (sensitivity Sensitivity) String() string {
+	switch sensitivity {
+	case SensitivityHigh:
+		return "high"
+	case SensitivityMedium:
+		return "medium"
+	case SensitivityLow:
+		return "low"
+	}
+	return "unknown"
+}
+
+// Sets the MinScore of the tagger to the one of the sensitivity
+func (copyrightTagger *Tagger) SetSensitivity(sensitivity Sensitivity) {
+	copyrightTagger.MinScore = sensitivity.MinScore()
+}
+
+// Returns the notices scoring at least minScore, in the same order
+func FilterNotices(notices []Notice, minScore float64) []Notice {
+	var kept = make([]Notice, 0, len(notices))
+	for _, notice := range notices {
+		if notice.Score >= minScore {
+			kept = append(kept, notice)
+		}
+	}
+	return kept
+}
+
+// Scores the words of one notice, mention is its mentionScore
+func (copyrightTagger *Tagger) noticeScore(words []TaggedWord, mention float64) float64 {
+	score := scoreMarker * strongestMarker(words)
+
+	if len(noticeYears(words)) > 0 {
+		years := ParseYears(toString(words), currentYear())
+		switch {
+		case len(years.Problems) == 0:
+			score += scoreYears
+		case len(years.Ranges) > 0:
+			score += scoreYears / 2
+		}
+	}
+	if hasHolder(words) {
+		score += scoreHolder
+	}
+	score += scoreTags * copyrightTagger.tagConfidence(words)
+
+	return score * (1 - mention)
+}
+
+// Returns the strength of the strongest marker in the words, a notice
+// like "Copyright © 2010" is as strong as ©. A notice with no known marker
+// is weakMarker.
+func strongestMarker(words []TaggedWord) float64 {
+	strongest := -1.0
+	for i := range words {
+		marker := noticeMarker(words[i:])
+		strength, ok := markerStrength[marker]
+		if !ok && isFuzzyCopyright(marker) {
+			strength, ok = fuzzyMarker, true
+		}
+		if ok && strength > strongest {
+			strongest = strength
+		}
+	}
+	if strongest < 0 {
+		return weakMarker
+	}
+	return strongest
+}
+
+// Returns on average how likely the dictionary says each word is to have
+// the tag it was given. Numbers are always sure, words the dictionary
+// does not know count as unknownTagConfidence and untagged words as 0.
+func (copyrightTagger *Tagger) tagConfidence(words []TaggedWord) float64 {
+	if len(words) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, word := range words {
+		switch {
+		case word.Tag == "":
+		case word.Tag == "cd":
+			total++
+		default:
+			frequencies := copyrightTagger.Dictionary[word.Text]
+			if len(frequencies) == 0 {
+				frequencies = copyrightTagger.Dictionary[strings.ToLower(word.Text)]
+			}
+			if len(frequencies) == 0 {
+				total += unknownTagConfidence
+			}
+			for _, frequency := range frequencies {
+				if frequency.tag == word.Tag {
+					total += float64(frequency.freq)
+				}
+			}
+		}
+	}
+	return total / float64(len(words))
+}
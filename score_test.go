@@ -0,0 +1,109 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Unit tests for notice scores and sensitivity
+
+package tagger
+
+import (
+	"testing"
+)
+
+func TestNoticeScore(t *testing.T) {
+	type ScoreTest struct {
+		Expected string // the text scoring lower
+		Text     string
+	}
+
+	tests := []ScoreTest{
+		// no years
+		{
+			Expected: "Copyright Alastair Houghton",
+			Text:     "Copyright 2007 Alastair Houghton",
+		},
+		// impossible years
+		{
+			Expected: "Copyright 1066 Alastair Houghton",
+			Text:     "Copyright 2007 Alastair Houghton",
+		},
+		// © is stronger than copyright
+		{
+			Expected: "Copyright 2007 Alastair Houghton",
+			Text:     "© 2007 Alastair Houghton",
+		},
+		// a misspelled copyright is weaker
+		{
+			Expected: "Copyrigth 2007 Alastair Houghton",
+			Text:     "Copyright 2007 Alastair Houghton",
+		},
+	}
+
+	for i, test := range tests {
+		lower := copyrightTagger.ExtractNotices([]byte(test.Expected))
+		higher := copyrightTagger.ExtractNotices([]byte(test.Text))
+		if len(lower) != 1 || len(higher) != 1 {
+			t.Errorf("Test %d: expected one notice each got %d and %d", i, len(lower), len(higher))
+			continue
+		}
+		if lower[0].Score >= higher[0].Score || (lower[0].Fuzzy && higher[0].Score-lower[0].Score < 0.1) {
+			t.Errorf("Test %d: expected %q (%f) to score lower than %q (%f)", i, test.Expected, lower[0].Score, test.Text, higher[0].Score)
+		}
+		for _, notice := range []Notice{lower[0], higher[0]} {
+			if notice.Score < 0 || notice.Score > 1 {
+				t.Errorf("Test %d: score %f of %q is not in [0,1]", i, notice.Score, notice.Text)
+			}
+		}
+	}
+}
+
+func TestSensitivity(t *testing.T) {
+	text := []byte("Copyright ( C ) and then Copyright (c) 2015 Eric Knapik, All Rights Reserved")
+
+	if copyrightTagger.MinScore != SensitivityHigh.MinScore() {
+		t.Errorf("expected New to use the high sensitivity")
+	}
+	all := copyrightTagger.ExtractNotices(text)
+	if len(all) != 2 {
+		t.Fatalf("expected 2 notices got %d", len(all))
+	}
+
+	low := *copyrightTagger
+	low.SetSensitivity(SensitivityLow)
+	notices := low.ExtractNotices(text)
+	if len(notices) != 1 || notices[0].Start != all[1].Start {
+		t.Errorf("expected only the second notice with low sensitivity got %v", notices)
+	}
+	if len(low.FindAllIndex(text)) != 1 || !low.Match(text) {
+		t.Errorf("expected FindAllIndex and Match to use the same MinScore")
+	}
+
+	filtered := FilterNotices(all, SensitivityLow.MinScore())
+	if len(filtered) != 1 || filtered[0].Start != all[1].Start {
+		t.Errorf("expected FilterNotices to keep the second notice got %v", filtered)
+	}
+}
@@ -0,0 +1,182 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// TagBytes tags its whole input as one sentence and returns one slice for
+// all of it. A SentenceReader reads an io.Reader a sentence at a time and
+// tags each on its own, so a big corpus can be tagged without holding it
+// all in memory. Nothing is read until Next is called, a slow consumer
+// slows the reading down, and reading stops once the context is done.
+//
+// A sentence ends at a ., ? or ! followed by white space, closing quotes
+// and brackets after it included, or at a blank line. A period after a
+// corporate ending like Inc. or after one letter, like the initial in
+// "J. Doe", does not end one. A sentence with no end gets cut at white
+// space after maxSentenceBytes and anywhere after twice that.
+
+package tagger
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"strings"
+)
+
+// how long a sentence gets before it is cut at the next white space
+const maxSentenceBytes int = 64 << 10
+
+// Reads and tags an io.Reader one sentence at a time, like a bufio.Scanner:
+//
+//	reader := copyrightTagger.TagReader(file)
+//	for reader.Next() {
+//		words := reader.Sentence()
+//	}
+//	if err := reader.Err(); err != nil {
+type SentenceReader struct {
+	tagger   *Tagger
+	ctx      context.Context
+	in       *bufio.Reader
+	buf      []byte
+	base     int // the offset of buf in the input
+	sentence []TaggedWord
+	eof      bool
+	err      error
+}
+
+// Returns a SentenceReader for the input
+func (copyrightTagger *Tagger) TagReader(in io.Reader) *SentenceReader {
+	return copyrightTagger.TagReaderContext(context.Background(), in)
+}
+
+// Returns a SentenceReader for the input that stops when the context is
+// done, Err is then the context's error
+func (copyrightTagger *Tagger) TagReaderContext(ctx context.Context, in io.Reader) *SentenceReader {
+	return &SentenceReader{
+		tagger: copyrightTagger,
+		ctx:    ctx,
+		in:     bufio.NewReader(in),
+	}
+}
+
+// Reads and tags the next sentence, false at the end of the input, when
+// reading failed or the context is done, see Err
+func (reader *SentenceReader) Next() bool {
+	reader.sentence = nil
+	for !reader.eof && reader.err == nil {
+		if err := reader.ctx.Err(); err != nil {
+			reader.err = err
+			return false
+		}
+		reader.readSentence()
+
+		words := reader.tagger.TagBytes(reader.buf)
+		for _, word := range words {
+			// trailing white space makes TagBytes add an empty word
+			if word.Text == "" {
+				continue
+			}
+			word.Start += reader.base
+			word.End += reader.base
+			reader.sentence = append(reader.sentence, word)
+		}
+		reader.base += len(reader.buf)
+		reader.buf = reader.buf[:0]
+		if len(reader.sentence) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the tagged words of the sentence Next read, the offsets are
+// from the start of the input
+func (reader *SentenceReader) Sentence() []TaggedWord {
+	return reader.sentence
+}
+
+// Returns the first error reading the input other than io.EOF, or the
+// context's error when it was done before the input ran out
+func (reader *SentenceReader) Err() error {
+	return reader.err
+}
+
+// Reads bytes into buf up to the end of the next sentence
+func (reader *SentenceReader) readSentence() {
+	for {
+		b, err := reader.in.ReadByte()
+		if err != nil {
+			if err != io.EOF {
+				reader.err = err
+			}
+			reader.eof = true
+			return
+		}
+		reader.buf = append(reader.buf, b)
+		if len(reader.buf) >= 2*maxSentenceBytes {
+			return
+		}
+		if !isSpace(b) {
+			continue
+		}
+		if len(reader.buf) >= maxSentenceBytes || sentenceEnds(reader.buf[:len(reader.buf)-1], b) {
+			return
+		}
+	}
+}
+
+// Returns true when text followed by the white space byte space ends
+// a sentence
+func sentenceEnds(text []byte, space byte) bool {
+	end := len(text)
+	if end == 0 {
+		return false
+	}
+	if isSpace(text[end-1]) {
+		// a blank line, white space on it or not
+		line := bytes.TrimRight(text, " \t\r")
+		return space == '\n' && len(line) > 0 && line[len(line)-1] == '\n'
+	}
+	for end > 0 && strings.IndexByte("\"')]", text[end-1]) >= 0 {
+		end--
+	}
+	if end == 0 || strings.IndexByte(".?!", text[end-1]) < 0 {
+		return false
+	}
+	if text[end-1] != '.' {
+		return true
+	}
+
+	// the word the period is on
+	start := end - 1
+	for start > 0 && !isSpace(text[start-1]) && !isSymbol(text[start-1]) {
+		start--
+	}
+	word := string(text[start : end-1])
+	return len(word) > 1 && !corporateSuffixes[strings.ToLower(word)]
+}
@@ -0,0 +1,116 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Unit tests for tagging a reader a sentence at a time
+
+package tagger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+// a reader counting how much was read from it
+type countingReader struct {
+	in   io.Reader
+	read int
+}
+
+func (reader *countingReader) Read(buf []byte) (int, error) {
+	n, err := reader.in.Read(buf)
+	reader.read += n
+	return n, err
+}
+
+func TestTagReader(t *testing.T) {
+	text := "Copyright 2012 Google Inc. All rights reserved. Is it J. Doe? " +
+		"Yes!\n\nA new paragraph\nwith no period\n  \nand the end"
+	expected := []string{
+		"Copyright 2012 Google Inc. All rights reserved.",
+		"Is it J. Doe?",
+		"Yes!",
+		"A new paragraph\nwith no period",
+		"and the end",
+	}
+
+	reader := copyrightTagger.TagReader(strings.NewReader(text))
+	i := 0
+	for ; reader.Next(); i++ {
+		words := reader.Sentence()
+		if i >= len(expected) {
+			t.Errorf("Test %d: unexpected sentence %q", i, toString(words))
+			continue
+		}
+		got := text[words[0].Start:words[len(words)-1].End]
+		if got != expected[i] {
+			t.Errorf("Test %d: expected %q got %q", i, expected[i], got)
+		}
+		for _, word := range words {
+			if word.Text == "" || !strings.HasPrefix(text[word.Start:], word.Text) {
+				t.Errorf("Test %d: word %q does not match offset %d", i, word.Text, word.Start)
+			}
+		}
+	}
+	if i != len(expected) {
+		t.Errorf("expected %d sentences got %d", len(expected), i)
+	}
+	if err := reader.Err(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestTagReaderContext(t *testing.T) {
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 2000)
+	in := &countingReader{in: strings.NewReader(text)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reader := copyrightTagger.TagReaderContext(ctx, in)
+	if !reader.Next() {
+		t.Fatalf("expected a sentence got %v", reader.Err())
+	}
+	// nothing is read ahead past what the reader buffers
+	if in.read >= len(text)/2 {
+		t.Errorf("expected only the start of the input read got %d bytes", in.read)
+	}
+
+	cancel()
+	if reader.Next() {
+		t.Errorf("expected no sentence after cancel")
+	}
+	if reader.Err() != context.Canceled {
+		t.Errorf("expected %v got %v", context.Canceled, reader.Err())
+	}
+
+	reader = copyrightTagger.TagReader(bytes.NewReader(nil))
+	if reader.Next() || reader.Err() != nil {
+		t.Errorf("expected nothing from an empty input got %v", reader.Err())
+	}
+}
@@ -0,0 +1,342 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// This file is about the machine readable SPDX tags many files carry,
+// "SPDX-License-Identifier: Apache-2.0 OR MIT" and
+// "SPDX-FileCopyrightText: 2020 Jane Doe <jane@x.org>". The tagger reads
+// them as prose and gets them wrong so they are parsed here instead. The
+// copyright texts are added to what ExtractNotices returns and the
+// license expressions to what ExtractLicenses returns.
+
+package tagger
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// the tags this file knows
+const (
+	SPDXLicenseIdentifier string = "SPDX-License-Identifier"
+	SPDXFileCopyrightText string = "SPDX-FileCopyrightText"
+)
+
+// One SPDX tag of the input
+type SPDXTag struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Start int    `json:"start"` // byte offset of the first byte of the value
+	End   int    `json:"end"`   // byte offset just past the value
+	// the parsed value of a SPDX-License-Identifier, nil when it is not
+	// a valid expression and for other tags
+	Expression *LicenseExpression `json:"-"`
+}
+
+// A SPDX license expression. A single license has no Op, a license with
+// an exception has the Op WITH and AND and OR join a Left and Right.
+type LicenseExpression struct {
+	Op        string
+	License   string
+	Exception string
+	Left      *LicenseExpression
+	Right     *LicenseExpression
+}
+
+// a tag and its value up to the end of the line
+var spdxTagPattern = regexp.MustCompile(`SPDX-(?:License-Identifier|FileCopyrightText)[ \t]*:[ \t]*([^\r\n]*)`)
+
+// what closes a comment after a tag on the same line
+var spdxValueEnds = []string{"*/", "-->", `"""`, "'''", "-}", "|#", "]]"}
+
+// anything in <> after a holder, an email or a web site
+var spdxContactPattern = regexp.MustCompile(`<[^<>]*>`)
+
+// a license or exception identifier, LicenseRef- ones included
+var spdxIDPattern = regexp.MustCompile(`^(?:DocumentRef-[A-Za-z0-9.\-]+:)?[A-Za-z0-9][A-Za-z0-9.\-]*\+?$`)
+
+// Given any input this returns every SPDX tag in it in order
+func ExtractSPDXTags(inBytes []byte) []SPDXTag {
+	var tags = make([]SPDXTag, 0)
+	for _, match := range spdxTagPattern.FindAllSubmatchIndex(inBytes, -1) {
+		key := string(inBytes[match[0]:match[1]])
+		key = strings.TrimSpace(key[:strings.Index(key, ":")])
+
+		value := string(inBytes[match[2]:match[3]])
+		for _, end := range spdxValueEnds {
+			if at := strings.Index(value, end); at >= 0 {
+				value = value[:at]
+			}
+		}
+		value = strings.TrimRight(value, " \t")
+		if value == "" {
+			continue
+		}
+
+		tag := SPDXTag{Key: key, Value: value, Start: match[2], End: match[2] + len(value)}
+		if key == SPDXLicenseIdentifier {
+			if expression, err := ParseLicenseExpression(value); err == nil {
+				tag.Expression = expression
+			}
+		}
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// Reads a SPDX license expression, WITH binds tighter than AND which
+// binds tighter than OR. The operators can be upper or lower case.
+func ParseLicenseExpression(text string) (*LicenseExpression, error) {
+	parser := expressionParser{tokens: lexLicenseExpression(text)}
+	if len(parser.tokens) == 0 {
+		return nil, fmt.Errorf("license expression is empty")
+	}
+	expression, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if parser.pos < len(parser.tokens) {
+		return nil, fmt.Errorf("license expression: unexpected %q", parser.tokens[parser.pos])
+	}
+	return expression, nil
+}
+
+// Writes the expression back out, with parentheses only where needed
+func (expression *LicenseExpression) String() string {
+	switch expression.Op {
+	case "":
+		return expression.License
+	case "WITH":
+		return expression.License + " WITH " + expression.Exception
+	}
+	return expression.Left.operand(expression.Op) + " " + expression.Op + " " + expression.Right.operand(expression.Op)
+}
+
+// the expression as one side of op, an OR inside an AND needs parentheses
+func (expression *LicenseExpression) operand(op string) string {
+	if op == "AND" && expression.Op == "OR" {
+		return "(" + expression.String() + ")"
+	}
+	return expression.String()
+}
+
+// Returns every license the expression names, each once, in order
+func (expression *LicenseExpression) Licenses() []string {
+	var licenses = make([]string, 0)
+	seen := make(map[string]bool)
+	var walk func(*LicenseExpression)
+	walk = func(node *LicenseExpression) {
+		if node.Op == "AND" || node.Op == "OR" {
+			walk(node.Left)
+			walk(node.Right)
+			return
+		}
+		if !seen[node.License] {
+			seen[node.License] = true
+			licenses = append(licenses, node.License)
+		}
+	}
+	walk(expression)
+	return licenses
+}
+
+// Cuts an expression into parentheses and words
+func lexLicenseExpression(text string) []string {
+	text = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(text)
+	return strings.Fields(text)
+}
+
+// a recursive descent parser over the words of an expression
+type expressionParser struct {
+	tokens []string
+	pos    int
+}
+
+// returns the next word as an operator if it is one
+func (parser *expressionParser) peekOp() string {
+	if parser.pos >= len(parser.tokens) {
+		return ""
+	}
+	switch op := strings.ToUpper(parser.tokens[parser.pos]); op {
+	case "AND", "OR", "WITH":
+		if parser.tokens[parser.pos] == op || parser.tokens[parser.pos] == strings.ToLower(op) {
+			return op
+		}
+	}
+	return ""
+}
+
+// or := and { OR and }
+func (parser *expressionParser) parseOr() (*LicenseExpression, error) {
+	left, err := parser.parseAnd()
+	for err == nil && parser.peekOp() == "OR" {
+		parser.pos++
+		var right *LicenseExpression
+		if right, err = parser.parseAnd(); err == nil {
+			left = &LicenseExpression{Op: "OR", Left: left, Right: right}
+		}
+	}
+	return left, err
+}
+
+// and := with { AND with }
+func (parser *expressionParser) parseAnd() (*LicenseExpression, error) {
+	left, err := parser.parseWith()
+	for err == nil && parser.peekOp() == "AND" {
+		parser.pos++
+		var right *LicenseExpression
+		if right, err = parser.parseWith(); err == nil {
+			left = &LicenseExpression{Op: "AND", Left: left, Right: right}
+		}
+	}
+	return left, err
+}
+
+// with := atom [ WITH exception ]
+func (parser *expressionParser) parseWith() (*LicenseExpression, error) {
+	atom, err := parser.parseAtom()
+	if err != nil || parser.peekOp() != "WITH" {
+		return atom, err
+	}
+	parser.pos++
+	if atom.Op != "" {
+		return nil, fmt.Errorf("license expression: WITH must follow a single license")
+	}
+	exception, err := parser.parseID()
+	if err != nil {
+		return nil, err
+	}
+	return &LicenseExpression{Op: "WITH", License: atom.License, Exception: exception}, nil
+}
+
+// atom := ( or ) | license
+func (parser *expressionParser) parseAtom() (*LicenseExpression, error) {
+	if parser.pos < len(parser.tokens) && parser.tokens[parser.pos] == "(" {
+		parser.pos++
+		inner, err := parser.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if parser.pos >= len(parser.tokens) || parser.tokens[parser.pos] != ")" {
+			return nil, fmt.Errorf("license expression: missing )")
+		}
+		parser.pos++
+		return inner, nil
+	}
+	license, err := parser.parseID()
+	if err != nil {
+		return nil, err
+	}
+	return &LicenseExpression{License: license}, nil
+}
+
+// reads one license or exception identifier
+func (parser *expressionParser) parseID() (string, error) {
+	if parser.pos >= len(parser.tokens) {
+		return "", fmt.Errorf("license expression: ends early")
+	}
+	id := parser.tokens[parser.pos]
+	if parser.peekOp() != "" || !spdxIDPattern.MatchString(id) {
+		return "", fmt.Errorf("license expression: %q is not a license", id)
+	}
+	parser.pos++
+	return id, nil
+}
+
+// Adds a notice for every SPDX-FileCopyrightText tag, the notices the
+// DFA found inside one are replaced by it
+func addSPDXNotices(inBytes []byte, taggedSent []TaggedWord, notices []Notice) []Notice {
+	for _, tag := range ExtractSPDXTags(inBytes) {
+		if tag.Key != SPDXFileCopyrightText {
+			continue
+		}
+		kept := notices[:0]
+		for _, notice := range notices {
+			if !(notice.Start < tag.End && tag.Start < notice.End) {
+				kept = append(kept, notice)
+			}
+		}
+		notices = append(kept, mkSPDXNotice(tag, taggedSent))
+	}
+
+	sort.SliceStable(notices, func(i, j int) bool {
+		return notices[i].Start < notices[j].Start
+	})
+	return notices
+}
+
+// Builds the Notice of a SPDX-FileCopyrightText tag, it is declared by
+// the author so it is as sure as a notice gets
+func mkSPDXNotice(tag SPDXTag, taggedSent []TaggedWord) Notice {
+	notice := Notice{
+		Start:    tag.Start,
+		End:      tag.End,
+		Text:     tag.Value,
+		Marker:   SPDXFileCopyrightText,
+		Years:    textYears(tag.Value),
+		Holders:  spdxHolders(tag.Value),
+		Words:    make([]TaggedWord, 0),
+		Score:    1,
+		Language: englishLanguage,
+	}
+	for _, word := range taggedSent {
+		if word.Start >= tag.Start && word.End <= tag.End && word.End > word.Start {
+			notice.Words = append(notice.Words, word)
+		}
+	}
+	notice.YearRanges = ParseYears(tag.Value, currentYear())
+	return notice
+}
+
+// Returns the holder of a SPDX-FileCopyrightText value without the email
+// or web site that often follows it, so the holder groups with the same
+// holder found anywhere else
+func spdxHolders(value string) []string {
+	value = spdxContactPattern.ReplaceAllString(value, " ")
+	value = authorEmailPattern.ReplaceAllString(value, " ")
+	return foreignHolders(value)
+}
+
+// Returns a LicenseMatch for every SPDX-License-Identifier tag with a
+// valid expression, its ID is the whole expression
+func declaredLicenses(inBytes []byte) []LicenseMatch {
+	var matches = make([]LicenseMatch, 0)
+	for _, tag := range ExtractSPDXTags(inBytes) {
+		if tag.Expression != nil {
+			matches = append(matches, LicenseMatch{
+				ID:       tag.Expression.String(),
+				Coverage: 1,
+				Start:    tag.Start,
+				End:      tag.End,
+				Declared: true,
+			})
+		}
+	}
+	return matches
+}
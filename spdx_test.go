@@ -0,0 +1,146 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Unit tests for SPDX tags and license expressions
+
+package tagger
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLicenseExpression(t *testing.T) {
+	type ExpressionTest struct {
+		Expected string   // the expression written back out
+		Licenses []string // the licenses it names
+		Text     string
+	}
+
+	tests := []ExpressionTest{
+		{
+			Expected: "MIT",
+			Licenses: []string{"MIT"},
+			Text:     "MIT",
+		},
+		{
+			Expected: "Apache-2.0 OR MIT",
+			Licenses: []string{"Apache-2.0", "MIT"},
+			Text:     "Apache-2.0 or MIT",
+		},
+		{
+			Expected: "GPL-2.0-or-later WITH Classpath-exception-2.0",
+			Licenses: []string{"GPL-2.0-or-later"},
+			Text:     "GPL-2.0-or-later WITH Classpath-exception-2.0",
+		},
+		{
+			Expected: "(MIT OR Apache-2.0) AND LicenseRef-Proprietary",
+			Licenses: []string{"MIT", "Apache-2.0", "LicenseRef-Proprietary"},
+			Text:     "(MIT OR Apache-2.0) AND LicenseRef-Proprietary",
+		},
+		// AND binds tighter than OR
+		{
+			Expected: "BSD-3-Clause OR MIT AND GPL-2.0+",
+			Licenses: []string{"BSD-3-Clause", "MIT", "GPL-2.0+"},
+			Text:     "BSD-3-Clause OR (MIT AND GPL-2.0+)",
+		},
+		{
+			Expected: "DocumentRef-spdx-tool-1.2:LicenseRef-MIT-Style-2 AND MIT",
+			Licenses: []string{"DocumentRef-spdx-tool-1.2:LicenseRef-MIT-Style-2", "MIT"},
+			Text:     "DocumentRef-spdx-tool-1.2:LicenseRef-MIT-Style-2 AND MIT",
+		},
+	}
+
+	for i, test := range tests {
+		expression, err := ParseLicenseExpression(test.Text)
+		if err != nil {
+			t.Errorf("Test %d: %v", i, err)
+			continue
+		}
+		if expression.String() != test.Expected {
+			t.Errorf("Test %d: expected %q got %q", i, test.Expected, expression.String())
+		}
+		if !reflect.DeepEqual(expression.Licenses(), test.Licenses) {
+			t.Errorf("Test %d: expected licenses %v got %v", i, test.Licenses, expression.Licenses())
+		}
+	}
+
+	for _, broken := range []string{"", "MIT OR", "(MIT", "MIT)", "AND MIT", "MIT Apache-2.0", "(MIT OR BSD) WITH X", "MIT WITH", "M!T"} {
+		if _, err := ParseLicenseExpression(broken); err == nil {
+			t.Errorf("expected an error for %q", broken)
+		}
+	}
+}
+
+func TestSPDXTags(t *testing.T) {
+	text := "/* SPDX-License-Identifier: Apache-2.0 OR MIT */\n" +
+		"// SPDX-FileCopyrightText: 2020 Jane Doe <jane@x.org>\n" +
+		"# SPDX-FileCopyrightText: © 2019-2021 Acme Inc.\n" +
+		"// SPDX-License-Identifier: not ( valid\n" +
+		"int main() {}\n"
+
+	tags := ExtractSPDXTags([]byte(text))
+	if len(tags) != 4 {
+		t.Fatalf("expected 4 tags got %d", len(tags))
+	}
+	if tags[0].Key != SPDXLicenseIdentifier || tags[0].Value != "Apache-2.0 OR MIT" || tags[0].Expression == nil {
+		t.Errorf("unexpected first tag %+v", tags[0])
+	}
+	if text[tags[1].Start:tags[1].End] != "2020 Jane Doe <jane@x.org>" {
+		t.Errorf("unexpected span of the second tag %q", text[tags[1].Start:tags[1].End])
+	}
+	if tags[3].Expression != nil {
+		t.Errorf("expected the last expression to be invalid")
+	}
+
+	notices := copyrightTagger.ExtractNotices([]byte(text))
+	if len(notices) != 2 {
+		t.Fatalf("expected 2 notices got %d", len(notices))
+	}
+	if notices[0].Marker != SPDXFileCopyrightText || !reflect.DeepEqual(notices[0].Holders, []string{"Jane Doe"}) ||
+		!reflect.DeepEqual(notices[0].Years, []int{2020}) {
+		t.Errorf("unexpected first notice %+v", notices[0])
+	}
+	if !reflect.DeepEqual(notices[1].Holders, []string{"Acme Inc"}) || notices[1].YearRanges.Earliest != 2019 {
+		t.Errorf("unexpected second notice %+v", notices[1])
+	}
+	for _, value := range []string{"2021 John Roe john@y.org", "2021 John Roe <https://y.org/john>"} {
+		notices := copyrightTagger.ExtractNotices([]byte("// SPDX-FileCopyrightText: " + value + "\n"))
+		if len(notices) != 1 || !reflect.DeepEqual(notices[0].Holders, []string{"John Roe"}) {
+			t.Errorf("expected the holder John Roe in %q got %v", value, notices)
+		}
+	}
+	if !copyrightTagger.Match([]byte(text)) || len(copyrightTagger.FindAllIndex([]byte(text))) != 2 {
+		t.Errorf("expected Match and FindAllIndex to see the tags")
+	}
+
+	licenses := copyrightTagger.ExtractLicenses([]byte(text))
+	if len(licenses) != 1 || licenses[0].ID != "Apache-2.0 OR MIT" || !licenses[0].Declared {
+		t.Errorf("expected the declared license got %v", licenses)
+	}
+}
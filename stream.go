@@ -0,0 +1,237 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Match, Extract and FindAllIndex want the whole input as one slice, which
+// does not work for logs and dumps of a few gigabytes. A NoticeReader
+// reads the input in chunks of ChunkSize bytes and runs ExtractNotices on
+// each. The last Overlap bytes of a chunk are read again at the start of
+// the next one so a notice crossing the end of a chunk is found whole in
+// the next, as long as it is shorter than Overlap. Chunks are cut at the
+// end of a line where one is near. Offsets and positions are counted from
+// the start of the input, not the chunk.
+
+package tagger
+
+import (
+	"bytes"
+	"io"
+	"unicode/utf8"
+)
+
+// how much of the input a NoticeReader tags at once
+const DefaultChunkSize int = 1 << 20
+
+// how much of a chunk a NoticeReader reads again with the next one
+const DefaultChunkOverlap int = 4 << 10
+
+// Reads the notices of an io.Reader one at a time, like a bufio.Scanner:
+//
+//	reader := copyrightTagger.NewNoticeReader(file)
+//	for reader.Next() {
+//		notice := reader.Notice()
+//	}
+//	if err := reader.Err(); err != nil {
+//
+// ChunkSize and Overlap can be changed before the first call to Next,
+// Overlap is at most a quarter of ChunkSize.
+type NoticeReader struct {
+	ChunkSize int
+	Overlap   int
+
+	tagger  *Tagger
+	in      io.Reader
+	buf     []byte
+	base    int      // the offset of buf in the input
+	basePos Position // and its position
+	seen    int      // the end of the last notice returned
+	pending []Notice
+	notice  Notice
+	eof     bool
+	done    bool
+	err     error
+}
+
+// Returns a NoticeReader for the input using the default chunk sizes
+func (copyrightTagger *Tagger) NewNoticeReader(in io.Reader) *NoticeReader {
+	return &NoticeReader{
+		ChunkSize: DefaultChunkSize,
+		Overlap:   DefaultChunkOverlap,
+		tagger:    copyrightTagger,
+		in:        in,
+		basePos:   Position{Line: 1, Column: 1, Column16: 1},
+	}
+}
+
+// Moves to the next notice, false when there are no more or reading the
+// input failed, see Err
+func (reader *NoticeReader) Next() bool {
+	for len(reader.pending) == 0 {
+		if reader.done {
+			return false
+		}
+		reader.scanChunk()
+	}
+	reader.notice = reader.pending[0]
+	reader.pending = reader.pending[1:]
+	return true
+}
+
+// Returns the notice Next moved to
+func (reader *NoticeReader) Notice() Notice {
+	return reader.notice
+}
+
+// Returns the first error reading the input other than io.EOF
+func (reader *NoticeReader) Err() error {
+	return reader.err
+}
+
+// Reads the next chunk, finds its notices and keeps the overlap
+func (reader *NoticeReader) scanChunk() {
+	chunkSize := reader.ChunkSize
+	if chunkSize < 1 {
+		chunkSize = DefaultChunkSize
+	}
+	// a chunk moves on by at least half of itself
+	overlap := reader.Overlap
+	if overlap < 0 || overlap > chunkSize/4 {
+		overlap = chunkSize / 4
+	}
+
+	if cap(reader.buf) < chunkSize {
+		buf := make([]byte, len(reader.buf), chunkSize)
+		copy(buf, reader.buf)
+		reader.buf = buf
+	}
+	n, err := io.ReadFull(reader.in, reader.buf[len(reader.buf):chunkSize])
+	reader.buf = reader.buf[:len(reader.buf)+n]
+	switch err {
+	case nil:
+	case io.EOF, io.ErrUnexpectedEOF:
+		reader.eof = true
+	default:
+		reader.err = err
+		reader.done = true
+		return
+	}
+
+	cut := len(reader.buf)
+	if !reader.eof {
+		cut = chunkCut(reader.buf, len(reader.buf)-overlap, overlap)
+	}
+	for _, notice := range reader.tagger.ExtractNotices(reader.buf) {
+		// notices past the cut are found again in the next chunk, ones
+		// before the end of the last notice were returned already
+		if notice.Start >= cut || reader.base+notice.Start < reader.seen {
+			continue
+		}
+		reader.pending = append(reader.pending, reader.shift(notice))
+		if notice.End+reader.base > reader.seen {
+			reader.seen = notice.End + reader.base
+		}
+	}
+
+	if reader.eof {
+		reader.done = true
+		reader.buf = nil
+		return
+	}
+	next := cut
+	if reader.seen-reader.base > next {
+		next = reader.seen - reader.base
+	}
+	reader.basePos = reader.basePos.add(Positions(reader.buf, []int{next})[0])
+	reader.base += next
+	reader.buf = append(reader.buf[:0], reader.buf[next:]...)
+}
+
+// Moves the offsets of a notice in the chunk to offsets in the input
+func (reader *NoticeReader) shift(notice Notice) Notice {
+	notice.Start += reader.base
+	notice.End += reader.base
+	words := make([]TaggedWord, len(notice.Words))
+	for i, word := range notice.Words {
+		word.Start += reader.base
+		word.End += reader.base
+		words[i] = word
+	}
+	notice.Words = words
+	notice.StartPos = reader.basePos.add(notice.StartPos)
+	notice.EndPos = reader.basePos.add(notice.EndPos)
+	return notice
+}
+
+// Returns the position in the whole input of pos, a position in a chunk
+// starting at base
+func (base Position) add(pos Position) Position {
+	if pos.Line == 1 {
+		pos.Column += base.Column - 1
+		pos.Column16 += base.Column16 - 1
+	}
+	pos.Line += base.Line - 1
+	pos.Offset += base.Offset
+	return pos
+}
+
+// Returns where to cut the chunk at or before at. The end of a line
+// within window bytes is best, then a space and then the start of a rune.
+func chunkCut(buf []byte, at int, window int) int {
+	low := at - window
+	if low < 0 {
+		low = 0
+	}
+	if i := bytes.LastIndexByte(buf[low:at], '\n'); i >= 0 {
+		return low + i + 1
+	}
+	if i := bytes.LastIndexByte(buf[low:at], ' '); i >= 0 {
+		return low + i + 1
+	}
+	for at > low && !utf8.RuneStart(buf[at]) {
+		at--
+	}
+	return at
+}
+
+// Returns true if the input has a copyright notice, it is read in chunks
+// and reading stops at the first notice
+func (copyrightTagger *Tagger) MatchReader(in io.Reader) (bool, error) {
+	reader := copyrightTagger.NewNoticeReader(in)
+	found := reader.Next()
+	return found, reader.Err()
+}
+
+// FindAllIndex for an input read in chunks
+func (copyrightTagger *Tagger) FindReaderIndex(in io.Reader) ([][]int, error) {
+	var indicies = make([][]int, 0)
+	reader := copyrightTagger.NewNoticeReader(in)
+	for reader.Next() {
+		notice := reader.Notice()
+		indicies = append(indicies, []int{notice.Start, notice.End})
+	}
+	return indicies, reader.Err()
+}
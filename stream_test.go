@@ -0,0 +1,153 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Unit tests for reading notices in chunks
+
+package tagger
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// a reader failing after the input it has
+type failingReader struct {
+	in io.Reader
+}
+
+func (reader failingReader) Read(buf []byte) (int, error) {
+	n, err := reader.in.Read(buf)
+	if err == io.EOF {
+		return n, errors.New("disk on fire")
+	}
+	return n, err
+}
+
+// filler lines with a notice every few lines
+func streamText(lines int) []byte {
+	var text bytes.Buffer
+	for i := 0; i < lines; i++ {
+		if i%7 == 3 {
+			text.WriteString("// Copyright 2010 Jane Doe\n")
+		} else {
+			text.WriteString("the quick brown fox jumps over the lazy dog\n")
+		}
+	}
+	return text.Bytes()
+}
+
+func TestNoticeReader(t *testing.T) {
+	type ReaderTest struct {
+		ChunkSize int
+		Overlap   int
+	}
+
+	text := streamText(60)
+	expected := copyrightTagger.ExtractNotices(text)
+	if len(expected) == 0 {
+		t.Fatalf("expected notices in the whole text")
+	}
+
+	tests := []ReaderTest{
+		{ChunkSize: DefaultChunkSize, Overlap: DefaultChunkOverlap},
+		{ChunkSize: 256, Overlap: 64},
+		{ChunkSize: 100, Overlap: 40}, // the overlap is cut to 25
+		{ChunkSize: 97, Overlap: 0},
+	}
+
+	for i, test := range tests {
+		reader := copyrightTagger.NewNoticeReader(bytes.NewReader(text))
+		reader.ChunkSize = test.ChunkSize
+		reader.Overlap = test.Overlap
+
+		var got []Notice
+		for reader.Next() {
+			got = append(got, reader.Notice())
+		}
+		if err := reader.Err(); err != nil {
+			t.Errorf("Test %d: unexpected error %v", i, err)
+		}
+		if test.Overlap == 0 {
+			// notices cut in two are lost but none are made up
+			for _, notice := range got {
+				if string(text[notice.Start:notice.End]) != notice.Text {
+					t.Errorf("Test %d: text %q does not match span [%d, %d]", i, notice.Text, notice.Start, notice.End)
+				}
+			}
+			continue
+		}
+		if len(got) != len(expected) {
+			t.Errorf("Test %d: expected %d notices got %d", i, len(expected), len(got))
+			continue
+		}
+		for j := range got {
+			if got[j].Start != expected[j].Start || got[j].End != expected[j].End || got[j].Text != expected[j].Text {
+				t.Errorf("Test %d: notice %d expected %q at [%d, %d] got %q at [%d, %d]", i, j,
+					expected[j].Text, expected[j].Start, expected[j].End, got[j].Text, got[j].Start, got[j].End)
+			}
+			if got[j].StartPos != expected[j].StartPos || got[j].EndPos != expected[j].EndPos {
+				t.Errorf("Test %d: notice %d expected %+v to %+v got %+v to %+v", i, j,
+					expected[j].StartPos, expected[j].EndPos, got[j].StartPos, got[j].EndPos)
+			}
+			if got[j].Words[0].Start != got[j].Start {
+				t.Errorf("Test %d: notice %d words not moved to input offsets", i, j)
+			}
+		}
+	}
+}
+
+func TestMatchReader(t *testing.T) {
+	found, err := copyrightTagger.MatchReader(bytes.NewReader(streamText(10)))
+	if !found || err != nil {
+		t.Errorf("expected a match got %v %v", found, err)
+	}
+	found, err = copyrightTagger.MatchReader(strings.NewReader("nothing to see here\n"))
+	if found || err != nil {
+		t.Errorf("expected no match got %v %v", found, err)
+	}
+
+	text := streamText(20)
+	indicies, err := copyrightTagger.FindReaderIndex(bytes.NewReader(text))
+	expected := copyrightTagger.FindAllIndex(text)
+	if err != nil || len(indicies) != len(expected) {
+		t.Fatalf("expected %v got %v %v", expected, indicies, err)
+	}
+	for i := range indicies {
+		if indicies[i][0] != expected[i][0] || indicies[i][1] != expected[i][1] {
+			t.Errorf("Test %d: expected %v got %v", i, expected[i], indicies[i])
+		}
+	}
+
+	_, err = copyrightTagger.FindReaderIndex(failingReader{strings.NewReader("Copyright 2010 Jane Doe")})
+	if err == nil {
+		t.Errorf("expected the read error")
+	}
+}
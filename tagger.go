@@ -57,15 +57,35 @@ type TagFrequency struct {
 type Tagger struct {
 	Dictionary  map[string][]TagFrequency
 	TransMatrix [][]float32
-	// for the copyright extraction
+	// for the copyright extraction, see grammar.go
+	Grammar *NoticeGrammar
+	// Deprecated: the notice DFA is Grammar now. These are the default
+	// grammar in the old table form for code that still reads them,
+	// changing them does nothing.
 	CopyrightDFA  map[Tri]int
 	CopyrightSyms string
+	// notices scoring this or more in mentionScore are mentions,
+	// see mention.go
+	MentionThreshold float64
+	// notices scoring less than this are dropped, see score.go
+	MinScore float64
+	// the licenses ExtractLicenses looks for, see licenses.go
+	Licenses []*LicenseTemplate
 }
 
+// A single word of the input and the part of speech it was tagged with.
+// Start and End are the byte offsets of the word in the tagged input,
+// when words are compressed back together End is the end of the last one.
 type TaggedWord struct {
-	word      string
-	tag       string
-	byteStart int
+	Text  string `json:"text"`
+	Tag   string `json:"tag"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// Returns the word in the same word|~|tag form the corpus uses
+func (taggedWord TaggedWord) String() string {
+	return taggedWord.Text + "|~|" + taggedWord.Tag
 }
 
 // three variable structure used in DFA translation
@@ -183,9 +203,18 @@ func New(path string) *Tagger {
 	convertTransMatrixToProb(&transMatrix)
 
 	// SETUP THE COPYRIGHT DFA
-	symbols, dfa := mkNoticeDFA()
-
-	return &Tagger{Dictionary: dictionary, TransMatrix: transMatrix, CopyrightDFA: dfa, CopyrightSyms: symbols}
+	grammar := DefaultNoticeGrammar()
+	symbols, dfa := grammar.legacyDFA()
+
+	return &Tagger{
+		Dictionary:       dictionary,
+		TransMatrix:      transMatrix,
+		Grammar:          grammar,
+		CopyrightDFA:     dfa,
+		CopyrightSyms:    symbols,
+		MentionThreshold: DefaultMentionThreshold,
+		Licenses:         DefaultLicenseTemplates(),
+	}
 }
 
 // This is the counter of tag transitions. Moving from one part of speech tag
@@ -382,23 +411,23 @@ func mkWrdArray(rawBytes []byte) []TaggedWord {
 	for currByte < len(rawBytes) {
 		if isSpace(rawBytes[currByte]) {
 			if wordStart != currByte { // add the word if I can
-				taggedWords = append(taggedWords, TaggedWord{word: string(rawBytes[wordStart:currByte]), tag: "", byteStart: wordStart})
+				taggedWords = append(taggedWords, TaggedWord{Text: string(rawBytes[wordStart:currByte]), Tag: "", Start: wordStart, End: currByte})
 			}
 			currByte++
 			wordStart = currByte
 		} else if isSymbol(rawBytes[currByte]) {
 			if wordStart != currByte { // add the word if I can
-				taggedWords = append(taggedWords, TaggedWord{word: string(rawBytes[wordStart:currByte]), tag: "", byteStart: wordStart})
+				taggedWords = append(taggedWords, TaggedWord{Text: string(rawBytes[wordStart:currByte]), Tag: "", Start: wordStart, End: currByte})
 			}
 			wordStart = currByte
 			currByte++
-			taggedWords = append(taggedWords, TaggedWord{word: string(rawBytes[wordStart:currByte]), tag: "", byteStart: wordStart})
+			taggedWords = append(taggedWords, TaggedWord{Text: string(rawBytes[wordStart:currByte]), Tag: "", Start: wordStart, End: currByte})
 			wordStart = currByte
 		} else {
 			currByte++
 		}
 	}
-	taggedWords = append(taggedWords, TaggedWord{word: string(rawBytes[wordStart:currByte]), tag: "", byteStart: wordStart})
+	taggedWords = append(taggedWords, TaggedWord{Text: string(rawBytes[wordStart:currByte]), Tag: "", Start: wordStart, End: currByte})
 	return taggedWords
 }
 
@@ -436,19 +465,19 @@ func (copyrightTagger *Tagger) TagBytes(rawBytes []byte) []TaggedWord {
 			var currTrans float32 = copyrightTagger.TransMatrix[lastBestTag][tagIndex]
 			var currProb float32 = lastBestProb * currTrans
 
-			if len(copyrightTagger.Dictionary[wrdArry[wrdIndex].word]) != 0 { // has the word been seen before?
-				if wrdArry[wrdIndex].word == "." || wrdArry[wrdIndex].word == "?" || wrdArry[wrdIndex].word == "!" {
+			if len(copyrightTagger.Dictionary[wrdArry[wrdIndex].Text]) != 0 { // has the word been seen before?
+				if wrdArry[wrdIndex].Text == "." || wrdArry[wrdIndex].Text == "?" || wrdArry[wrdIndex].Text == "!" {
 					sentMatrix[TagStrToInt["."]][wrdIndex+1] = 1.0
 				} else {
-					for _, tagObject := range copyrightTagger.Dictionary[wrdArry[wrdIndex].word] {
+					for _, tagObject := range copyrightTagger.Dictionary[wrdArry[wrdIndex].Text] {
 						if TagIntToStr[tagIndex] == tagObject.tag {
 							sentMatrix[tagIndex][wrdIndex+1] = currProb * tagObject.freq
 						}
 					}
 				}
 				// check for the word not carring about capitalization
-			} else if len(copyrightTagger.Dictionary[strings.ToLower(wrdArry[wrdIndex].word)]) != 0 {
-				for _, tagObject := range copyrightTagger.Dictionary[strings.ToLower(wrdArry[wrdIndex].word)] {
+			} else if len(copyrightTagger.Dictionary[strings.ToLower(wrdArry[wrdIndex].Text)]) != 0 {
+				for _, tagObject := range copyrightTagger.Dictionary[strings.ToLower(wrdArry[wrdIndex].Text)] {
 					if TagIntToStr[tagIndex] == tagObject.tag {
 						sentMatrix[tagIndex][wrdIndex+1] = currProb * tagObject.freq
 					}
@@ -457,7 +486,7 @@ func (copyrightTagger *Tagger) TagBytes(rawBytes []byte) []TaggedWord {
 				if currTrans >= 0.7 {
 					sentMatrix[tagIndex][wrdIndex+1] = currProb
 				} else {
-					likelyTag := tagUnkown(wrdArry[wrdIndex].word)
+					likelyTag := tagUnkown(wrdArry[wrdIndex].Text)
 					sentMatrix[TagStrToInt[likelyTag]][wrdIndex+1] = currProb * 0.95
 				}
 			}
@@ -477,7 +506,7 @@ func (copyrightTagger *Tagger) TagBytes(rawBytes []byte) []TaggedWord {
 		for tagIndex := 0; tagIndex < numOfTags; tagIndex++ {
 			if sentMatrix[tagIndex][wrdIndex+1] > tagProb {
 				tagProb = sentMatrix[tagIndex][wrdIndex+1]
-				wrdArry[wrdIndex].tag = TagIntToStr[tagIndex]
+				wrdArry[wrdIndex].Tag = TagIntToStr[tagIndex]
 			}
 		}
 	}
@@ -542,10 +571,10 @@ func compressNumInString(inSent []TaggedWord) []TaggedWord {
 
 	for _, taggedWord := range inSent {
 		// Make the transition to the next state based on the input
-		if taggedWord.tag == "." {
-			currentState = dfa[Tri{state: currentState, word: taggedWord.word, pos: taggedWord.tag}]
-		} else if taggedWord.tag == "cd" {
-			currentState = dfa[Tri{state: currentState, word: "X", pos: taggedWord.tag}]
+		if taggedWord.Tag == "." {
+			currentState = dfa[Tri{state: currentState, word: taggedWord.Text, pos: taggedWord.Tag}]
+		} else if taggedWord.Tag == "cd" {
+			currentState = dfa[Tri{state: currentState, word: "X", pos: taggedWord.Tag}]
 		} else {
 			currentState = dfa[Tri{state: currentState, word: "X", pos: "X"}]
 		}
@@ -555,8 +584,8 @@ func compressNumInString(inSent []TaggedWord) []TaggedWord {
 			finalSent = append(finalSent, saveNum...)
 			compNum = nil
 			saveNum = nil
-			compNum = append(compNum, taggedWord.word)
-			saveStartByte = taggedWord.byteStart
+			compNum = append(compNum, taggedWord.Text)
+			saveStartByte = taggedWord.Start
 			saveNum = append(saveNum, taggedWord)
 		} else if currentState == INTERM {
 			compNum = append(compNum, ".")
@@ -566,9 +595,9 @@ func compressNumInString(inSent []TaggedWord) []TaggedWord {
 			saveNum = nil
 			finalSent = append(finalSent, taggedWord)
 		} else if currentState == ACCEPT {
-			compNum = append(compNum, taggedWord.word)
+			compNum = append(compNum, taggedWord.Text)
 			saveNum = nil
-			saveNum = append(saveNum, TaggedWord{word: strings.Join(compNum, ""), tag: "cd", byteStart: saveStartByte})
+			saveNum = append(saveNum, TaggedWord{Text: strings.Join(compNum, ""), Tag: "cd", Start: saveStartByte, End: taggedWord.End})
 			currentState = START
 		}
 	}
@@ -589,31 +618,33 @@ func compressNP(inSent []TaggedWord) []TaggedWord {
 	prevTag := ""
 	var saveWord []string = make([]string, 0)
 	var saveByteStart int
+	var saveByteEnd int
 	for _, taggedWord := range inSent {
 
-		if prevTag == "np" && taggedWord.word == "." {
+		if prevTag == "np" && taggedWord.Text == "." {
 			saveWord = append(saveWord, ".")
-			finalSent = append(finalSent, TaggedWord{word: strings.Join(saveWord, ""), tag: "np", byteStart: saveByteStart})
+			finalSent = append(finalSent, TaggedWord{Text: strings.Join(saveWord, ""), Tag: "np", Start: saveByteStart, End: taggedWord.End})
 			saveWord = nil
-		} else if prevTag == "np" && taggedWord.tag == "np" {
-			finalSent = append(finalSent, TaggedWord{word: strings.Join(saveWord, ""), tag: "np", byteStart: saveByteStart})
+		} else if prevTag == "np" && taggedWord.Tag == "np" {
+			finalSent = append(finalSent, TaggedWord{Text: strings.Join(saveWord, ""), Tag: "np", Start: saveByteStart, End: saveByteEnd})
 			saveWord = nil
-			saveWord = append(saveWord, taggedWord.word)
-		} else if prevTag == "np" && taggedWord.word != "." {
-			finalSent = append(finalSent, TaggedWord{word: strings.Join(saveWord, ""), tag: "np", byteStart: saveByteStart}, taggedWord)
+			saveWord = append(saveWord, taggedWord.Text)
+		} else if prevTag == "np" && taggedWord.Text != "." {
+			finalSent = append(finalSent, TaggedWord{Text: strings.Join(saveWord, ""), Tag: "np", Start: saveByteStart, End: saveByteEnd}, taggedWord)
 			saveWord = nil
-		} else if taggedWord.tag == "np" {
-			saveWord = append(saveWord, taggedWord.word)
+		} else if taggedWord.Tag == "np" {
+			saveWord = append(saveWord, taggedWord.Text)
 		} else {
 			finalSent = append(finalSent, taggedWord)
 		}
 
-		saveByteStart = taggedWord.byteStart
-		prevTag = taggedWord.tag
+		saveByteStart = taggedWord.Start
+		saveByteEnd = taggedWord.End
+		prevTag = taggedWord.Tag
 
 	}
 	if prevTag == "np" {
-		finalSent = append(finalSent, TaggedWord{word: strings.Join(saveWord, ""), tag: "np", byteStart: saveByteStart})
+		finalSent = append(finalSent, TaggedWord{Text: strings.Join(saveWord, ""), Tag: "np", Start: saveByteStart, End: saveByteEnd})
 	}
 
 	return finalSent
@@ -622,7 +653,7 @@ func compressNP(inSent []TaggedWord) []TaggedWord {
 func toString(inSent []TaggedWord) string {
 	var finalSent = make([]string, 0)
 	for _, taggedWord := range inSent {
-		finalSent = append(finalSent, taggedWord.word)
+		finalSent = append(finalSent, taggedWord.Text)
 	}
 	return strings.Join(finalSent, " ")
 }
@@ -33,9 +33,12 @@ POSSIBILITY OF SUCH DAMAGE.
 package tagger
 
 import (
+	"encoding/json"
 	"log"
 	"os"
+	"strings"
 	"testing"
+	"testing/quick"
 )
 
 var copyrightTagger *Tagger
@@ -252,6 +255,51 @@ func TestMatch(t *testing.T) {
 	}
 }
 
+// words that push the notice DFA around, random text made out of these
+// hits most of its states
+var noticeVocabulary = []string{
+	"Copyright", "copyright", "COPYRIGHT", "(", "c", "C", ")", "©", "Â©",
+	"2007", "1999", "3.1", ",", "-", "--", ".", "Free", "Software", "Foundation",
+	"Inc.", "IBM", "and", "by", "the", "of", "with", "<", "/", "*", "%s", "\\(co",
+	"All", "rights", "reserved", "license", "\n", "",
+}
+
+// Match has to agree with FindAllIndex on any input since they
+// are both built on scanNotices
+func TestMatchAgreesWithFindAllIndex(t *testing.T) {
+	agree := func(picks []uint8, glue []bool) bool {
+		var text []string
+		for i, pick := range picks {
+			word := noticeVocabulary[int(pick)%len(noticeVocabulary)]
+			if i < len(glue) && glue[i] && len(text) > 0 {
+				text[len(text)-1] += word
+			} else {
+				text = append(text, word)
+			}
+		}
+		raw := []byte(strings.Join(text, " "))
+		return copyrightTagger.Match(raw) == (len(copyrightTagger.FindAllIndex(raw)) > 0)
+	}
+	if err := quick.Check(agree, &quick.Config{MaxCount: 2000}); err != nil {
+		t.Error(err)
+	}
+
+	// and on every input the other tests use
+	for i, text := range []string{
+		"Copyright (c) IBM       Corporation, 2003,   2008.  All rights reserved.   --",
+		" #define Copyright sign ",
+		"COPYRIGHT SIGN */ (1U<<_CC_GRAPH)|(1U<<_CC_PRINT)|(1U<<_CC_QUOTEMETA),",
+		"(c)   ",
+		"© 2010",
+		"",
+	} {
+		raw := []byte(text)
+		if copyrightTagger.Match(raw) != (len(copyrightTagger.FindAllIndex(raw)) > 0) {
+			t.Errorf("Test %d: Match and FindAllIndex disagree on %q", i, text)
+		}
+	}
+}
+
 /*
  * XXX - Tad: this should probably be more robust than just checking the length of the tagged words array that was returned
  */
@@ -277,6 +325,40 @@ func TestTagBytes(t *testing.T) {
 	}
 }
 
+func TestTaggedWordOffsets(t *testing.T) {
+	raw := "Copyright (c) 2007, 2008 Alastair Houghton\n" +
+		"Version 3.1.2 of Free Software Foundation, Inc. is here"
+
+	twords := copyrightTagger.TagBytes([]byte(raw))
+	if len(twords) == 0 {
+		t.Fatalf("expected tagged words, got none")
+	}
+
+	for i, tword := range twords {
+		if tword.Start > tword.End || tword.End > len(raw) {
+			t.Errorf("word %d %q: bad offsets [%d, %d]", i, tword.Text, tword.Start, tword.End)
+			continue
+		}
+		// compressed words lose the white space between their parts
+		spanned := strings.Join(strings.Fields(raw[tword.Start:tword.End]), "")
+		if spanned != tword.Text {
+			t.Errorf("word %d: expected %q at [%d, %d] got %q", i, tword.Text, tword.Start, tword.End, spanned)
+		}
+	}
+
+	tword := TaggedWord{Text: "Copyright", Tag: "nn", Start: 0, End: 9}
+	if tword.String() != "Copyright|~|nn" {
+		t.Errorf("expected %q got %q", "Copyright|~|nn", tword.String())
+	}
+	encoded, err := json.Marshal(tword)
+	if err != nil {
+		t.Fatalf("could not marshal tagged word: %v", err)
+	}
+	if string(encoded) != `{"text":"Copyright","tag":"nn","start":0,"end":9}` {
+		t.Errorf("unexpected JSON %s", encoded)
+	}
+}
+
 func TestFindAllIndex(t *testing.T) {
 	raw := "It's an MIT-style license.  Here goes:\n"+
 		"\n"+
@@ -287,7 +369,9 @@ func TestFindAllIndex(t *testing.T) {
 		"to use, copy, modify, merge, publish, distribute, sublicense, and/or sell\n"+
 		"copies of the Software, and to permit persons to whom the Software is\n"+
 		"furnished to do so, subject to the following conditions:"
-	expected := [][]int{{40, 94}}
+	// the notice ends just past Houghton, Permission only closed it and
+	// the newline between them is not part of it
+	expected := [][]int{{40, 82}}
 
 	matches := copyrightTagger.FindAllIndex([]byte(raw))
 	if matches == nil {
@@ -330,7 +414,7 @@ func TestExtract(t *testing.T) {
 					" copyright ( ( copyright ( ( ( ( ( ( ( ( Copyright ( C ) < ( < < Copyright ( C ) < (",
 		},
 		{
-			Expected:	"Copyright 1999 , 2002 - 2003 , 2005 - 2007 , 2009 - 2011 Free",
+			Expected:	"© Copyright 1999 , 2002 - 2003 , 2005 - 2007 , 2009 - 2011 Free",
 			Text:		"/* Decomposed printf argument list.\n"+
 					" laksjdf laskdj f;l © Copyright 1999, 2002-2003, 2005-2007, 2009-2011 Free Software\n"+
 					"    Foundation, Inc.\n"+
@@ -0,0 +1,272 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// This file is about reading the years out of a notice.
+// The notice DFA only sees a chain of cd, comma and dash tags, this goes
+// back over the text of a notice and turns "2003,2005-2007",
+// "1999 - present" or "'98" into sorted year ranges that can be compared.
+
+package tagger
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// no copyright notice on anything this package scans is older than this
+const earliestNoticeYear int = 1900
+
+// reasons a year in a notice was flagged
+const (
+	YearImpossible = "impossible" // the year is before earliestNoticeYear
+	YearFuture     = "future"     // the year is after the current year
+	YearBackwards  = "backwards"  // the range ends before it starts
+)
+
+// A range of years, a single year has From equal to To
+type YearRange struct {
+	From    int  `json:"from"`
+	To      int  `json:"to"`
+	Present bool `json:"present,omitempty"` // open ended like "1999 - present", To is the current year
+}
+
+// A year that was found but looks wrong
+type YearProblem struct {
+	Text   string `json:"text"`   // the year or range as it was written
+	Reason string `json:"reason"` // one of YearImpossible, YearFuture or YearBackwards
+}
+
+// The years of a notice normalized into sorted ranges that do not overlap.
+// Impossible and backwards years are left out of the ranges, future
+// years are kept but all of them are listed in the Problems.
+type NoticeYears struct {
+	Ranges   []YearRange   `json:"ranges"`
+	Earliest int           `json:"earliest"` // zero when there are no ranges
+	Latest   int           `json:"latest"`   // zero when there are no ranges
+	Problems []YearProblem `json:"problems,omitempty"`
+}
+
+// the kinds of pieces the year text is broken up into
+const (
+	yearPiece    int = iota // a four digit year or an abbreviated '98
+	shortPiece              // a two digit number that can only end a range, 2005-07
+	dashPiece               // -, – or the word to
+	presentPiece            // present, now or today ending a range
+	listPiece               // a comma or the word and between years
+	otherPiece              // anything else, ends whatever range was going
+)
+
+type yearToken struct {
+	kind  int
+	value int
+	text  string
+}
+
+// Given the text of a notice, or just the year part of one, this returns
+// the years in it as normalized ranges. Now is the current year and is
+// used for open ended ranges, abbreviated years and spotting future years.
+func ParseYears(text string, now int) NoticeYears {
+	var years = NoticeYears{Ranges: make([]YearRange, 0)}
+	var found = make([]YearRange, 0)
+
+	pending := -1 // index into tokens of a year that might start a range
+	dashed := false
+	tokens := lexYears(text, now)
+
+	addRange := func(from int, to int, present bool, written string) {
+		switch {
+		case from < earliestNoticeYear || to < earliestNoticeYear:
+			years.Problems = append(years.Problems, YearProblem{Text: written, Reason: YearImpossible})
+			return
+		case to < from:
+			years.Problems = append(years.Problems, YearProblem{Text: written, Reason: YearBackwards})
+			return
+		case to > now && !present:
+			years.Problems = append(years.Problems, YearProblem{Text: written, Reason: YearFuture})
+		}
+		found = append(found, YearRange{From: from, To: to, Present: present})
+	}
+	flush := func() {
+		if pending >= 0 {
+			addRange(tokens[pending].value, tokens[pending].value, false, tokens[pending].text)
+		}
+		pending = -1
+		dashed = false
+	}
+
+	for i, token := range tokens {
+		switch {
+		case dashed && token.kind == yearPiece:
+			addRange(tokens[pending].value, token.value, false, tokens[pending].text+"-"+token.text)
+			pending = -1
+			dashed = false
+		case dashed && token.kind == shortPiece:
+			// 2005-07 ends in the same century as it starts unless that goes backwards
+			to := tokens[pending].value - tokens[pending].value%100 + token.value
+			if to < tokens[pending].value {
+				to += 100
+			}
+			addRange(tokens[pending].value, to, false, tokens[pending].text+"-"+token.text)
+			pending = -1
+			dashed = false
+		case dashed && token.kind == presentPiece:
+			addRange(tokens[pending].value, now, true, tokens[pending].text+"-"+token.text)
+			pending = -1
+			dashed = false
+		case token.kind == dashPiece && pending >= 0 && !dashed:
+			dashed = true
+		case token.kind == yearPiece:
+			flush()
+			pending = i
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	years.Ranges = mergeYearRanges(found)
+	if len(years.Ranges) > 0 {
+		years.Earliest = years.Ranges[0].From
+		years.Latest = years.Ranges[len(years.Ranges)-1].To
+	}
+	return years
+}
+
+// Sorts the ranges and joins any that overlap or touch, 2007, 2008
+// becomes 2007-2008
+func mergeYearRanges(found []YearRange) []YearRange {
+	var merged = make([]YearRange, 0)
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].From < found[j].From
+	})
+	for _, yearRange := range found {
+		last := len(merged) - 1
+		if last >= 0 && yearRange.From <= merged[last].To+1 {
+			if yearRange.To > merged[last].To {
+				merged[last].To = yearRange.To
+			}
+			merged[last].Present = merged[last].Present || yearRange.Present
+			continue
+		}
+		merged = append(merged, yearRange)
+	}
+	return merged
+}
+
+// Breaks the text into the pieces ParseYears cares about
+func lexYears(text string, now int) []yearToken {
+	var tokens = make([]yearToken, 0)
+	runes := []rune(text)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || (isApostrophe(r) && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			if isApostrophe(r) {
+				i++
+			}
+			for i < len(runes) && unicode.IsDigit(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, mkYearToken(string(runes[start:i]), now))
+		case r == '-' || r == '–' || r == '—':
+			for i < len(runes) && (runes[i] == '-' || runes[i] == '–' || runes[i] == '—') {
+				i++
+			}
+			tokens = append(tokens, yearToken{kind: dashPiece, text: "-"})
+		case r == ',' || r == '&' || r == ';':
+			tokens = append(tokens, yearToken{kind: listPiece, text: string(r)})
+			i++
+		case unicode.IsLetter(r):
+			start := i
+			for i < len(runes) && unicode.IsLetter(runes[i]) {
+				i++
+			}
+			word := string(runes[start:i])
+			switch strings.ToLower(word) {
+			case "to", "through", "thru", "until":
+				tokens = append(tokens, yearToken{kind: dashPiece, text: word})
+			case "present", "now", "today", "current", "date":
+				tokens = append(tokens, yearToken{kind: presentPiece, text: word})
+			case "and":
+				tokens = append(tokens, yearToken{kind: listPiece, text: word})
+			default:
+				tokens = append(tokens, yearToken{kind: otherPiece, text: word})
+			}
+		default:
+			tokens = append(tokens, yearToken{kind: otherPiece, text: string(r)})
+			i++
+		}
+	}
+	return tokens
+}
+
+// Works out what a run of digits is, a full year, an abbreviated year
+// like '98, a two digit range end or some other number
+func mkYearToken(text string, now int) yearToken {
+	if isApostrophe([]rune(text)[0]) {
+		digits := string([]rune(text)[1:])
+		value, err := strconv.Atoi(digits)
+		if err != nil || len(digits) != 2 {
+			return yearToken{kind: otherPiece, text: text}
+		}
+		// '98 is 1998 but '05 is 2005, anything not after this year is this century
+		century := now - now%100
+		if century+value > now {
+			century -= 100
+		}
+		return yearToken{kind: yearPiece, value: century + value, text: text}
+	}
+
+	value, err := strconv.Atoi(text)
+	switch {
+	case err != nil:
+		return yearToken{kind: otherPiece, text: text}
+	case len(text) == 4:
+		return yearToken{kind: yearPiece, value: value, text: text}
+	case len(text) == 2:
+		return yearToken{kind: shortPiece, value: value, text: text}
+	}
+	return yearToken{kind: otherPiece, text: text}
+}
+
+// the different ways an abbreviated year is written
+func isApostrophe(r rune) bool {
+	return r == '\'' || r == '’' || r == '‘' || r == '`'
+}
+
+// the year notices are checked against for future years
+func currentYear() int {
+	return time.Now().Year()
+}
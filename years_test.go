@@ -0,0 +1,153 @@
+/*
+Copyright (c) 2015 Eric Knapik, All Rights Reserved
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions
+are met:
+
+  1. Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright
+     notice, this list of conditions and the following disclaimer in the
+     documentation and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Unit tests for reading years out of notices
+
+package tagger
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseYears(t *testing.T) {
+	type YearTest struct {
+		Ranges   []YearRange
+		Earliest int
+		Latest   int
+		Problems []YearProblem
+		Text     string
+	}
+
+	now := 2016
+	tests := []YearTest{
+		{
+			Ranges:   []YearRange{{From: 2007, To: 2008}},
+			Earliest: 2007,
+			Latest:   2008,
+			Text:     "2007, 2008",
+		},
+		{
+			Ranges:   []YearRange{{From: 2001, To: 2015}},
+			Earliest: 2001,
+			Latest:   2015,
+			Text:     "Copyright (c) 2001-2015 Acme",
+		},
+		{
+			Ranges:   []YearRange{{From: 1999, To: 2016, Present: true}},
+			Earliest: 1999,
+			Latest:   2016,
+			Text:     "1999 - present",
+		},
+		{
+			Ranges:   []YearRange{{From: 1998, To: 1998}},
+			Earliest: 1998,
+			Latest:   1998,
+			Text:     "(C) '98 Foo",
+		},
+		{
+			Ranges:   []YearRange{{From: 2003, To: 2003}, {From: 2005, To: 2007}},
+			Earliest: 2003,
+			Latest:   2007,
+			Text:     "2003,2005-2007",
+		},
+		{
+			Ranges:   []YearRange{{From: 1999, To: 1999}, {From: 2002, To: 2003}, {From: 2005, To: 2007}, {From: 2009, To: 2011}},
+			Earliest: 1999,
+			Latest:   2011,
+			Text:     "Copyright (C) 1999, 2002-2003, 2005-2007, 2009-2011 Free Software",
+		},
+		{
+			Ranges:   []YearRange{{From: 2005, To: 2007}, {From: 2014, To: 2014}},
+			Earliest: 2005,
+			Latest:   2014,
+			Text:     "2014, 2005-07",
+		},
+		{
+			Ranges:   []YearRange{{From: 2003, To: 2003}, {From: 2020, To: 2020}},
+			Earliest: 2003,
+			Latest:   2020,
+			Problems: []YearProblem{{Text: "1066", Reason: YearImpossible}, {Text: "2020", Reason: YearFuture}, {Text: "2010-2004", Reason: YearBackwards}},
+			Text:     "1066, 2003, 2020 and 2010-2004",
+		},
+		{
+			Ranges: []YearRange{},
+			Text:   "Copyright Free Software Foundation, Inc. 51 Franklin Street",
+		},
+	}
+
+	for i, test := range tests {
+		r := ParseYears(test.Text, now)
+		if !reflect.DeepEqual(r.Ranges, test.Ranges) {
+			t.Errorf("Test %d: expected ranges %v got %v", i, test.Ranges, r.Ranges)
+		}
+		if r.Earliest != test.Earliest || r.Latest != test.Latest {
+			t.Errorf("Test %d: expected %d to %d got %d to %d", i, test.Earliest, test.Latest, r.Earliest, r.Latest)
+		}
+		if !reflect.DeepEqual(r.Problems, test.Problems) {
+			t.Errorf("Test %d: expected problems %v got %v", i, test.Problems, r.Problems)
+		}
+	}
+}
+
+// the years of whole notices, the grammar has to keep every year form in
+// the notice for ParseYears to see it
+func TestNoticeYears(t *testing.T) {
+	type NoticeYearTest struct {
+		Notice   string
+		Earliest int
+		Present  bool // the last range runs to the present
+		Ranges   int
+		Text     string
+	}
+
+	tests := []NoticeYearTest{
+		{Notice: "Copyright 2007, 2008 Foo Corp.", Earliest: 2007, Ranges: 1, Text: "Copyright 2007, 2008 Foo Corp.\n"},
+		{Notice: "Copyright 2001-2015 Foo Corp.", Earliest: 2001, Ranges: 1, Text: "Copyright 2001-2015 Foo Corp.\n"},
+		{Notice: "Copyright (c) 1999 - present The Foo Authors.", Earliest: 1999, Present: true, Ranges: 1, Text: "Copyright (c) 1999 - present The Foo Authors.\n"},
+		{Notice: "Copyright 1999-present Foo Corp.", Earliest: 1999, Present: true, Ranges: 1, Text: "Copyright 1999-present Foo Corp.\n"},
+		{Notice: "Copyright '98 Foo Corp.", Earliest: 1998, Ranges: 1, Text: "Copyright '98 Foo Corp.\n"},
+		{Notice: "Copyright 2003,2005-2007 Foo Corp.", Earliest: 2003, Ranges: 2, Text: "Copyright 2003,2005-2007 Foo Corp.\n"},
+	}
+
+	for i, test := range tests {
+		notices := copyrightTagger.ExtractNotices([]byte(test.Text))
+		if len(notices) != 1 || notices[0].Text != test.Notice {
+			t.Errorf("Test %d: expected the notice %q got %v", i, test.Notice, notices)
+			continue
+		}
+		years := notices[0].YearRanges
+		if years.Earliest != test.Earliest || len(years.Ranges) != test.Ranges {
+			t.Errorf("Test %d: expected %d ranges from %d got %v", i, test.Ranges, test.Earliest, years)
+			continue
+		}
+		if years.Ranges[len(years.Ranges)-1].Present != test.Present {
+			t.Errorf("Test %d: expected present %v got %v", i, test.Present, years.Ranges)
+		}
+	}
+}